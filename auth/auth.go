@@ -1,13 +1,15 @@
 package auth
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"net/http"
 	"os"
+	"path/filepath"
+	"sync"
 
 	"urodstvo-launcher/minecraft"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"github.com/wailsapp/wails/v3/pkg/application"
 )
@@ -15,22 +17,37 @@ import (
 type AuthService struct {
 	clientId     string
 	redirectURI  string
-	state        string
-	codeVerifier string
+
+	attemptMu sync.Mutex
+	attempt   *loginAttempt
 
 	app          *application.App
 	authWindow   *application.WebviewWindow
+
+	deviceCodeMu     sync.Mutex
+	cancelDeviceCode context.CancelFunc
+}
+
+// DeviceCodeLogin is what the user needs to finish a device code login on another device.
+type DeviceCodeLogin struct {
+	UserCode        string `json:"userCode"`
+	VerificationURI string `json:"verificationUri"`
+	ExpiresIn       int    `json:"expiresIn"`
+	Message         string `json:"message"`
 }
 
-func NewAuthService() *AuthService {
-	if err := godotenv.Load(".env"); err != nil{
-		fmt.Println("not founded .env")
+func NewAuthService(dataDir string) *AuthService {
+	// The data directory wins; a .env next to the working directory is still read for development.
+	for _, path := range []string{filepath.Join(dataDir, ".env"), ".env"} {
+		if err := godotenv.Load(path); err == nil {
+			break
+		}
 	}
 
 	clientId := os.Getenv("MICROSOFT_CLIENT_ID")
 	redirectURI := os.Getenv("MICROSOFT_REDIRECT_URI")
-	if clientId == "" || redirectURI == "" {
-		fmt.Println("CLIENT_ID & REDIRECT_URI not found in .env")
+	if clientId == "" {
+		fmt.Println("MICROSOFT_CLIENT_ID not found in .env")
 	}
 
 	return &AuthService{
@@ -48,52 +65,96 @@ func (a *AuthService) SetApp(app *application.App) {
 		Height: 800,
 		Title: "Microsoft Login",
 	})
+}
+
+func (a *AuthService) AddMicrosoftAccount() error {
+	attempt, loginURL, err := a.startLoginAttempt()
+	if err != nil {
+		return err
+	}
+
+	a.attemptMu.Lock()
+	if a.attempt != nil {
+		go a.attempt.close()
+	}
+	a.attempt = attempt
+	a.attemptMu.Unlock()
+
+	a.authWindow.SetURL(loginURL)
 
-	a.startRedirectServer()
+	a.authWindow.Show()
+	return nil
 }
 
-func (a *AuthService) startRedirectServer() {
-	http.HandleFunc("/auth-callback", func(w http.ResponseWriter, r *http.Request) {
-		code, err := minecraft.ParseAuthCodeURL(r.URL.String(), &a.state)
-		if err != nil {
-			a.app.EmitEvent("auth:microsoft:failed", err.Error())
-			a.authWindow.Hide()
+// StartDeviceCodeLogin signs in without the embedded webview or the redirect server, e.g. over SSH.
+// The result arrives as the usual auth:microsoft:success or auth:microsoft:failed event.
+func (a *AuthService) StartDeviceCodeLogin() (DeviceCodeLogin, error) {
+	code, err := minecraft.RequestDeviceCode(a.clientId)
+	if err != nil {
+		return DeviceCodeLogin{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.deviceCodeMu.Lock()
+	if a.cancelDeviceCode != nil {
+		a.cancelDeviceCode()
+	}
+	a.cancelDeviceCode = cancel
+	a.deviceCodeMu.Unlock()
+
+	go func() {
+		defer cancel()
+
+		resp, err := minecraft.CompleteDeviceCodeLogin(ctx, a.clientId, code)
+		if ctx.Err() != nil {
 			return
 		}
-
-		resp, err := minecraft.CompleteLogin(a.clientId, "", a.redirectURI, code, a.codeVerifier)
 		if err != nil {
-			a.app.EmitEvent("auth:microsoft:failed", err.Error())
-			a.authWindow.Hide()
+			a.app.EmitEvent("auth:microsoft:failed", minecraft.AsAuthError(err))
 			return
 		}
 		a.app.EmitEvent("auth:microsoft:success", resp)
-		a.authWindow.Hide()
-	})
-
-	go func() {
-		err := http.ListenAndServe(":34115", nil)
-		if err != nil {
-			log.Println("redirect server error:", err)
-		}
 	}()
+
+	return DeviceCodeLogin{
+		UserCode: code.UserCode,
+		VerificationURI: code.VerificationURI,
+		ExpiresIn: code.ExpiresIn,
+		Message: code.Message,
+	}, nil
 }
 
-func (a *AuthService) AddMicrosoftAccount() error {
-	loginURL, state, codeVerifier, err := minecraft.GetSecureLoginData(a.clientId, a.redirectURI, nil)
-	if err != nil {
-		return fmt.Errorf("failed to get login URL: %w", err)
+func (a *AuthService) CancelDeviceCodeLogin() {
+	a.deviceCodeMu.Lock()
+	defer a.deviceCodeMu.Unlock()
+
+	if a.cancelDeviceCode != nil {
+		a.cancelDeviceCode()
+		a.cancelDeviceCode = nil
 	}
+}
 
-	a.state = state
-	a.codeVerifier = codeVerifier
+// RefreshLogin exchanges a stored refresh token for a fresh Minecraft access token.
+func (a *AuthService) RefreshLogin(refreshToken string) (*minecraft.CompleteLoginResponse, error) {
+	return minecraft.CompleteRefresh(a.clientId, "", refreshToken)
+}
 
-	a.authWindow.SetURL(loginURL)
+// AddYggdrasilAccount signs in to a third-party Yggdrasil server; server may be its API root or homepage.
+func (a *AuthService) AddYggdrasilAccount(server, username, password string) error {
+	login, err := minecraft.CompleteYggdrasilLogin(server, username, password, uuid.New().String())
+	if err != nil {
+		return err
+	}
 
-	a.authWindow.Show()
+	a.app.EmitEvent("auth:yggdrasil:success", login)
 	return nil
 }
 
-func (a *AuthService) AddFreeAccount(username string) {
+func (a *AuthService) AddFreeAccount(username string) error {
+	if err := minecraft.ValidateUsername(username); err != nil {
+		return err
+	}
+
 	a.app.EmitEvent("auth:free:success", username)
+	return nil
 }
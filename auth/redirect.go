@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"urodstvo-launcher/minecraft"
+)
+
+const (
+	_defaultRedirectURI = "http://127.0.0.1/auth-callback"
+	_loginTimeout = 5 * time.Minute
+)
+
+var _redirectPage = template.Must(template.New("redirect").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #1e1e1e; color: #eee; display: flex; align-items: center; justify-content: center; height: 100vh; margin: 0; }
+main { max-width: 28rem; text-align: center; }
+a { color: #7cc4ff; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+{{if .HelpURL}}<p><a href="{{.HelpURL}}" target="_blank" rel="noopener">Learn more</a></p>{{end}}
+<p>You can close this window and return to the launcher.</p>
+</main>
+</body>
+</html>
+`))
+
+// loginAttempt owns everything for one authorization code login: its PKCE verifier, its state and the
+// loopback listener that receives the redirect. A new attempt never touches an older one.
+type loginAttempt struct {
+	state string
+	codeVerifier string
+	redirectURI string
+
+	server *http.Server
+	once sync.Once
+	closeOnce sync.Once
+	finished chan struct{}
+}
+
+// listenRedirect binds 127.0.0.1 on the port from the configured redirect URI, or an ephemeral one when it
+// has none, and returns the redirect URI with the port that was actually bound.
+func listenRedirect(configured string) (net.Listener, string, error) {
+	if configured == "" {
+		configured = _defaultRedirectURI
+	}
+	u, err := url.Parse(configured)
+	if err != nil {
+		return nil, "", err
+	}
+	if u.Scheme != "http" {
+		return nil, "", errors.New("redirect URI must be a plain http loopback address")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "0"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", port))
+	if err != nil {
+		return nil, "", err
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	u.Host = net.JoinHostPort(host, strconv.Itoa(listener.Addr().(*net.TCPAddr).Port))
+	if u.Path == "" {
+		u.Path = "/auth-callback"
+	}
+	return listener, u.String(), nil
+}
+
+// startLoginAttempt opens the listener for a new login and returns it with the Microsoft login URL to show.
+func (a *AuthService) startLoginAttempt() (*loginAttempt, string, error) {
+	listener, redirectURI, err := listenRedirect(a.redirectURI)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start redirect listener: %w", err)
+	}
+
+	loginURL, state, codeVerifier, err := minecraft.GetSecureLoginData(a.clientId, redirectURI, nil)
+	if err != nil {
+		listener.Close()
+		return nil, "", fmt.Errorf("failed to get login URL: %w", err)
+	}
+
+	attempt := &loginAttempt{
+		state: state,
+		codeVerifier: codeVerifier,
+		redirectURI: redirectURI,
+		finished: make(chan struct{}),
+	}
+	callbackPath := redirectURI
+	if u, err := url.Parse(redirectURI); err == nil {
+		callbackPath = u.Path
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
+		handled := false
+		attempt.once.Do(func() {
+			handled = true
+			a.handleRedirect(attempt, w, r)
+		})
+		if !handled {
+			http.Error(w, "This sign-in link has already been used.", http.StatusGone)
+		}
+	})
+	attempt.server = &http.Server{
+		Handler: mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go attempt.server.Serve(listener)
+	go func() {
+		select {
+		case <-attempt.finished:
+		case <-time.After(_loginTimeout):
+			attempt.once.Do(func() {
+				a.app.EmitEvent("auth:microsoft:failed", minecraft.ErrLoginTimedOut)
+				a.authWindow.Hide()
+				go attempt.close()
+			})
+		}
+	}()
+
+	return attempt, loginURL, nil
+}
+
+func (a *AuthService) handleRedirect(attempt *loginAttempt, w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		a.authWindow.Hide()
+		go attempt.close()
+	}()
+
+	resp, err := a.redeemRedirect(attempt, r)
+	if err != nil {
+		authErr := minecraft.AsAuthError(err)
+		a.app.EmitEvent("auth:microsoft:failed", authErr)
+
+		w.WriteHeader(http.StatusBadRequest)
+		_redirectPage.Execute(w, map[string]string{
+			"Title": "Sign-in failed",
+			"Message": authErr.Message,
+			"HelpURL": authErr.HelpURL,
+		})
+		return
+	}
+
+	a.app.EmitEvent("auth:microsoft:success", resp)
+	_redirectPage.Execute(w, map[string]string{
+		"Title": "Signed in",
+		"Message": "Signed in as " + resp.Name + ".",
+	})
+}
+
+func (a *AuthService) redeemRedirect(attempt *loginAttempt, r *http.Request) (*minecraft.CompleteLoginResponse, error) {
+	code, err := minecraft.ParseAuthCodeURL(r.URL.String(), &attempt.state)
+	if err != nil {
+		return nil, err
+	}
+	return minecraft.CompleteLogin(a.clientId, "", attempt.redirectURI, code, attempt.codeVerifier)
+}
+
+// close stops the listener once the response has been written; it is safe to call more than once.
+func (attempt *loginAttempt) close() {
+	attempt.closeOnce.Do(func() {
+		close(attempt.finished)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		attempt.server.Shutdown(ctx)
+	})
+}
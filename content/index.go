@@ -0,0 +1,181 @@
+package content
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"urodstvo-launcher/minecraft"
+)
+
+const (
+	_indexFileName = ".index.json"
+	disabledSuffix = ".disabled"
+)
+
+func getIndexPath(gameDir string) string {
+	return filepath.Join(gameDir, "mods", _indexFileName)
+}
+
+func ReadIndex(gameDir string) (Index, error) {
+	index := Index{Mods: []IndexEntry{}}
+
+	data, err := os.ReadFile(getIndexPath(gameDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return index, err
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return index, err
+	}
+	if index.Mods == nil {
+		index.Mods = []IndexEntry{}
+	}
+	return index, nil
+}
+
+func writeIndex(gameDir string, index Index) error {
+	path := getIndexPath(gameDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func (index *Index) find(projectId string) int {
+	return slices.IndexFunc(index.Mods, func(entry IndexEntry) bool {
+		return entry.ProjectId == projectId
+	})
+}
+
+func (index *Index) upsert(entry IndexEntry) {
+	if i := index.find(entry.ProjectId); i >= 0 {
+		existing := index.Mods[i]
+		entry.Dependency = entry.Dependency && existing.Dependency
+		for _, requiredBy := range existing.RequiredBy {
+			if !slices.Contains(entry.RequiredBy, requiredBy) {
+				entry.RequiredBy = append(entry.RequiredBy, requiredBy)
+			}
+		}
+		index.Mods[i] = entry
+		return
+	}
+	index.Mods = append(index.Mods, entry)
+}
+
+// addRequiredBy records that requiredBy depends on an installed mod, so RemoveMod keeps it while it is needed.
+// It reports whether the mod is installed.
+func (index *Index) addRequiredBy(projectId, requiredBy string) bool {
+	i := index.find(projectId)
+	if i < 0 {
+		return false
+	}
+	if entry := &index.Mods[i]; requiredBy != "" && !slices.Contains(entry.RequiredBy, requiredBy) {
+		entry.RequiredBy = append(entry.RequiredBy, requiredBy)
+	}
+	return true
+}
+
+func removeModFile(gameDir, fileName string) error {
+	if fileName == "" || filepath.Base(fileName) != fileName {
+		return nil
+	}
+
+	path := filepath.Join(gameDir, "mods", fileName)
+	for _, p := range []string{path, path + disabledSuffix} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveMod deletes an installed mod and any dependencies that were only installed for it.
+func RemoveMod(projectId string, options Options) ([]IndexEntry, error) {
+	index, err := ReadIndex(options.GameDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []IndexEntry{}
+	queue := []string{projectId}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		i := index.find(id)
+		if i < 0 {
+			continue
+		}
+		entry := index.Mods[i]
+
+		if err := removeModFile(options.GameDirectory, entry.FileName); err != nil {
+			return removed, err
+		}
+		index.Mods = slices.Delete(index.Mods, i, i+1)
+		removed = append(removed, entry)
+
+		for j := range index.Mods {
+			dependency := &index.Mods[j]
+			if !slices.Contains(dependency.RequiredBy, id) {
+				continue
+			}
+			dependency.RequiredBy = slices.DeleteFunc(dependency.RequiredBy, func(r string) bool { return r == id })
+			if dependency.Dependency && len(dependency.RequiredBy) == 0 {
+				queue = append(queue, dependency.ProjectId)
+			}
+		}
+	}
+
+	return removed, writeIndex(options.GameDirectory, index)
+}
+
+// ReplaceIndexedFiles keeps the index in sync when tracked mod files are updated outside this package. The
+// index is written once, so it either records every update or none of them.
+func ReplaceIndexedFiles(gameDir string, updates []minecraft.ModUpdate) error {
+	index, err := ReadIndex(gameDir)
+	if err != nil {
+		return err
+	}
+
+	// Entries are matched before any are renamed, since an update may take over another mod's old file name.
+	matches := make([]int, len(updates))
+	for u, update := range updates {
+		matches[u] = slices.IndexFunc(index.Mods, func(entry IndexEntry) bool {
+			return strings.EqualFold(entry.FileName, update.FileName)
+		})
+	}
+
+	changed := false
+	for u, update := range updates {
+		i := matches[u]
+		if i < 0 {
+			continue
+		}
+
+		index.Mods[i].VersionId = update.NewVersionId
+		index.Mods[i].Version = update.NewVersion
+		index.Mods[i].FileName = update.NewFile.Filename
+		index.Mods[i].Hashes = update.NewFile.Hashes
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return writeIndex(gameDir, index)
+}
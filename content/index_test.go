@@ -0,0 +1,54 @@
+package content
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"urodstvo-launcher/minecraft"
+)
+
+func TestReplaceIndexedFiles(t *testing.T) {
+	gameDir := t.TempDir()
+	index := Index{Mods: []IndexEntry{
+		{ProjectId: "sodium", VersionId: "s1", Version: "0.5", FileName: "sodium.jar", Hashes: map[string]string{"sha1": "a"}},
+		{ProjectId: "lithium", VersionId: "l1", Version: "0.12", FileName: "lithium-old.jar", Hashes: map[string]string{"sha1": "b"}},
+	}}
+	if err := writeIndex(gameDir, index); err != nil {
+		t.Fatal(err)
+	}
+
+	// The lithium update takes over the name sodium used to have, which must not touch the sodium entry twice.
+	updates := []minecraft.ModUpdate{
+		{FileName: "SODIUM.jar", NewVersionId: "s2", NewVersion: "0.6", NewFile: minecraft.ModrinthVersionFile{Filename: "sodium-0.6.jar", Hashes: map[string]string{"sha1": "c"}}},
+		{FileName: "lithium-old.jar", NewVersionId: "l2", NewVersion: "0.13", NewFile: minecraft.ModrinthVersionFile{Filename: "sodium.jar", Hashes: map[string]string{"sha1": "d"}}},
+		{FileName: "untracked.jar", NewVersionId: "u2", NewFile: minecraft.ModrinthVersionFile{Filename: "untracked-2.jar"}},
+	}
+	if err := ReplaceIndexedFiles(gameDir, updates); err != nil {
+		t.Fatalf("ReplaceIndexedFiles: %v", err)
+	}
+
+	got, err := ReadIndex(gameDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []IndexEntry{
+		{ProjectId: "sodium", VersionId: "s2", Version: "0.6", FileName: "sodium-0.6.jar", Hashes: map[string]string{"sha1": "c"}},
+		{ProjectId: "lithium", VersionId: "l2", Version: "0.13", FileName: "sodium.jar", Hashes: map[string]string{"sha1": "d"}},
+	}
+	if !reflect.DeepEqual(got.Mods, want) {
+		t.Errorf("index =\n%+v\nwant\n%+v", got.Mods, want)
+	}
+}
+
+func TestReplaceIndexedFilesUntracked(t *testing.T) {
+	gameDir := t.TempDir()
+
+	updates := []minecraft.ModUpdate{{FileName: "untracked.jar", NewFile: minecraft.ModrinthVersionFile{Filename: "untracked-2.jar"}}}
+	if err := ReplaceIndexedFiles(gameDir, updates); err != nil {
+		t.Fatalf("ReplaceIndexedFiles: %v", err)
+	}
+	if _, err := os.Stat(getIndexPath(gameDir)); !os.IsNotExist(err) {
+		t.Error("an index was written although no tracked file changed")
+	}
+}
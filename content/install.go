@@ -0,0 +1,306 @@
+package content
+
+import (
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"urodstvo-launcher/minecraft"
+)
+
+var (
+	ErrorNoCompatibleVersion error = errors.New("no compatible version found")
+	ErrorIncompatibleMod     error = errors.New("mod is incompatible with an installed mod")
+)
+
+const (
+	_installStagingDirectory = ".install-staging"
+	_installBackupDirectory  = ".install-backup"
+)
+
+type resolvedMod struct {
+	version    Version
+	file       VersionFile
+	dependency bool
+	requiredBy []string
+}
+
+type resolver struct {
+	options  Options
+	index    *Index
+	resolved []*resolvedMod
+}
+
+func (r *resolver) get(projectId string) *resolvedMod {
+	for _, mod := range r.resolved {
+		if mod.version.ProjectId == projectId {
+			return mod
+		}
+	}
+	return nil
+}
+
+func (r *resolver) resolve(projectId, versionId, requiredBy string, dependency bool) error {
+	var version Version
+	if versionId != "" {
+		v, err := GetVersion(versionId, r.options)
+		if err != nil {
+			return err
+		}
+		if projectId != "" && v.ProjectId != projectId {
+			return fmt.Errorf("version %s does not belong to project %s", versionId, projectId)
+		}
+		version = v
+	} else {
+		if projectId == "" {
+			return nil
+		}
+		if mod := r.get(projectId); mod != nil {
+			mod.addRequiredBy(requiredBy)
+			return nil
+		}
+		if dependency && r.index.addRequiredBy(projectId, requiredBy) {
+			return nil
+		}
+
+		versions, err := GetProjectVersions(projectId, r.options)
+		if err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			return fmt.Errorf("%w: %s for %s %s", ErrorNoCompatibleVersion, projectId, r.options.Loader, r.options.GameVersion)
+		}
+		version = versions[0]
+	}
+
+	if mod := r.get(version.ProjectId); mod != nil {
+		mod.addRequiredBy(requiredBy)
+		return nil
+	}
+	if dependency && r.index.addRequiredBy(version.ProjectId, requiredBy) {
+		return nil
+	}
+
+	file, found := getPrimaryFile(version)
+	if !found {
+		return fmt.Errorf("version %s has no files", version.Id)
+	}
+
+	mod := &resolvedMod{version: version, file: file, dependency: dependency}
+	mod.addRequiredBy(requiredBy)
+	r.resolved = append(r.resolved, mod)
+
+	for _, dep := range version.Dependencies {
+		depProjectId, depVersionId := "", ""
+		if dep.ProjectId != nil {
+			depProjectId = *dep.ProjectId
+		}
+		if dep.VersionId != nil {
+			depVersionId = *dep.VersionId
+		}
+
+		switch dep.DependencyType {
+		case "required":
+			if err := r.resolve(depProjectId, depVersionId, version.ProjectId, true); err != nil {
+				return fmt.Errorf("failed to resolve dependency of %s: %w", version.Name, err)
+			}
+		case "incompatible":
+			if depProjectId != "" && (r.index.find(depProjectId) >= 0 || r.get(depProjectId) != nil) {
+				return fmt.Errorf("%w: %s conflicts with %s", ErrorIncompatibleMod, version.ProjectId, depProjectId)
+			}
+		}
+	}
+	return nil
+}
+
+func (mod *resolvedMod) addRequiredBy(projectId string) {
+	if projectId != "" && !slices.Contains(mod.requiredBy, projectId) {
+		mod.requiredBy = append(mod.requiredBy, projectId)
+	}
+}
+
+func getPrimaryFile(version Version) (VersionFile, bool) {
+	for _, file := range version.Files {
+		if file.Primary {
+			return file, true
+		}
+	}
+	if len(version.Files) > 0 {
+		return version.Files[0], true
+	}
+	return VersionFile{}, false
+}
+
+func downloadModFile(file VersionFile, modsDir string) error {
+	if file.Filename == "" || filepath.Base(file.Filename) != file.Filename {
+		return fmt.Errorf("invalid file name: %q", file.Filename)
+	}
+
+	req, err := http.NewRequest("GET", file.Url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "urodstvo-launcher/"+minecraft.GetLibraryVersion())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", file.Filename, resp.Status)
+	}
+
+	tmpPath := filepath.Join(modsDir, file.Filename+".part")
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	sha1Hash, sha512Hash := sha1.New(), sha512.New()
+	_, err = io.Copy(io.MultiWriter(out, sha1Hash, sha512Hash), resp.Body)
+	out.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	for algorithm, h := range map[string]hash.Hash{"sha1": sha1Hash, "sha512": sha512Hash} {
+		expected := file.Hashes[algorithm]
+		if computed := hex.EncodeToString(h.Sum(nil)); expected != "" && computed != expected {
+			os.Remove(tmpPath)
+			return fmt.Errorf("invalid %s checksum for %s: expected %s, got %s", algorithm, file.Filename, expected, computed)
+		}
+	}
+
+	return os.Rename(tmpPath, filepath.Join(modsDir, file.Filename))
+}
+
+// InstallMod installs a mod and its required dependencies. An empty versionId picks the newest compatible version.
+func InstallMod(projectId, versionId string, options Options, callback *minecraft.Callback) ([]IndexEntry, error) {
+	if callback == nil {
+		callback = &minecraft.Callback{}
+	}
+	if callback.Status == nil {
+		callback.Status = func(string) {}
+	}
+	if callback.Progress == nil {
+		callback.Progress = func(string) {}
+	}
+	if callback.Max == nil {
+		callback.Max = func(string) {}
+	}
+
+	index, err := ReadIndex(options.GameDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	callback.Status("Resolving Dependencies...")
+	r := &resolver{options: options, index: &index}
+	if err := r.resolve(projectId, versionId, "", false); err != nil {
+		return nil, err
+	}
+
+	modsDir := filepath.Join(options.GameDirectory, "mods")
+	stagingDir := filepath.Join(modsDir, _installStagingDirectory)
+	backupDir := filepath.Join(modsDir, _installBackupDirectory)
+
+	os.RemoveAll(stagingDir)
+	defer os.RemoveAll(stagingDir)
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return nil, err
+	}
+
+	callback.Status("Downloading Mods...")
+	callback.Progress("0")
+	callback.Max(strconv.Itoa(len(r.resolved)))
+
+	for i, mod := range r.resolved {
+		if err := downloadModFile(mod.file, stagingDir); err != nil {
+			return nil, err
+		}
+		callback.Progress(strconv.Itoa(i + 1))
+	}
+
+	os.RemoveAll(backupDir)
+	defer os.RemoveAll(backupDir)
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return nil, err
+	}
+
+	// Files that already exist are moved aside rather than overwritten, so a failed install puts them back.
+	var backedUp, placed []string
+	rollback := func() {
+		for _, fileName := range placed {
+			os.Remove(filepath.Join(modsDir, fileName))
+		}
+		for _, fileName := range backedUp {
+			os.Rename(filepath.Join(backupDir, fileName), filepath.Join(modsDir, fileName))
+		}
+	}
+
+	for _, mod := range r.resolved {
+		fileName := mod.file.Filename
+		target := filepath.Join(modsDir, fileName)
+		if _, err := os.Stat(target); err == nil {
+			if err := os.Rename(target, filepath.Join(backupDir, fileName)); err != nil {
+				rollback()
+				return nil, err
+			}
+			backedUp = append(backedUp, fileName)
+		}
+		if err := os.Rename(filepath.Join(stagingDir, fileName), target); err != nil {
+			rollback()
+			return nil, err
+		}
+		placed = append(placed, fileName)
+	}
+
+	var replaced []string
+	installed := []IndexEntry{}
+	for _, mod := range r.resolved {
+		if i := index.find(mod.version.ProjectId); i >= 0 && index.Mods[i].FileName != mod.file.Filename {
+			replaced = append(replaced, index.Mods[i].FileName)
+		}
+
+		entry := IndexEntry{
+			ProjectId:   mod.version.ProjectId,
+			VersionId:   mod.version.Id,
+			Version:     mod.version.VersionNumber,
+			FileName:    mod.file.Filename,
+			Hashes:      mod.file.Hashes,
+			Dependency:  mod.dependency,
+			RequiredBy:  mod.requiredBy,
+			InstalledAt: time.Now().UTC().Format(time.RFC3339),
+		}
+		index.upsert(entry)
+		installed = append(installed, entry)
+	}
+
+	if err := writeIndex(options.GameDirectory, index); err != nil {
+		rollback()
+		return nil, err
+	}
+
+	// Older versions of updated mods are only removed once the index no longer points at them.
+	for _, fileName := range replaced {
+		if !slices.Contains(placed, fileName) {
+			removeModFile(options.GameDirectory, fileName)
+		}
+	}
+
+	callback.Status(fmt.Sprintf("Installed %d mods.", len(installed)))
+	return installed, nil
+}
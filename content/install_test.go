@@ -0,0 +1,366 @@
+package content
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"urodstvo-launcher/minecraft"
+)
+
+func testDependency(projectId, dependencyType string) VersionDependency {
+	return VersionDependency{ProjectId: &projectId, DependencyType: dependencyType}
+}
+
+// testVersion describes a Modrinth version whose primary file holds "<id> contents".
+func testVersion(id, projectId string, dependencies ...VersionDependency) Version {
+	sum := sha1.Sum([]byte(id + " contents"))
+	return Version{
+		Id:            id,
+		ProjectId:     projectId,
+		Name:          projectId + " " + id,
+		VersionNumber: id,
+		Files: []VersionFile{{
+			Filename: id + ".jar",
+			Primary:  true,
+			Hashes:   map[string]string{"sha1": hex.EncodeToString(sum[:])},
+		}},
+		Dependencies: dependencies,
+	}
+}
+
+// newContentServer stands in for the Modrinth API. Versions are listed newest first, and every file is served
+// from /files/ unless its name is in missing.
+func newContentServer(t *testing.T, versions []Version, missing ...string) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		withURLs := func(version Version) Version {
+			version.Files = slices.Clone(version.Files)
+			for i := range version.Files {
+				version.Files[i].Url = server.URL + "/files/" + version.Files[i].Filename
+			}
+			return version
+		}
+
+		switch path := r.URL.Path; {
+		case strings.HasPrefix(path, "/files/"):
+			name := strings.TrimPrefix(path, "/files/")
+			if slices.Contains(missing, name) {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(strings.TrimSuffix(name, ".jar") + " contents"))
+		case strings.HasPrefix(path, "/version/"):
+			for _, version := range versions {
+				if version.Id == strings.TrimPrefix(path, "/version/") {
+					json.NewEncoder(w).Encode(withURLs(version))
+					return
+				}
+			}
+			http.NotFound(w, r)
+		case strings.HasPrefix(path, "/project/") && strings.HasSuffix(path, "/version"):
+			if loaders := r.URL.Query().Get("loaders"); !strings.Contains(loaders, `"fabric"`) {
+				t.Errorf("version list for loaders %s, want fabric", loaders)
+			}
+			projectId := strings.TrimSuffix(strings.TrimPrefix(path, "/project/"), "/version")
+			result := []Version{}
+			for _, version := range versions {
+				if version.ProjectId == projectId {
+					result = append(result, withURLs(version))
+				}
+			}
+			json.NewEncoder(w).Encode(result)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+var _testVersions = []Version{
+	testVersion("sodium-extra-0.6", "sodium-extra", testDependency("sodium", "required"), testDependency("iris", "required"), testDependency("reeses", "optional")),
+	testVersion("iris-1.8", "iris", testDependency("sodium", "required")),
+	testVersion("sodium-0.6", "sodium"),
+	testVersion("sodium-0.5", "sodium"),
+	testVersion("optifabric-1.14", "optifabric", testDependency("sodium", "incompatible")),
+	testVersion("broken-1.0", "broken", testDependency("missing", "required")),
+	{Id: "empty-1.0", ProjectId: "empty"},
+}
+
+func TestResolve(t *testing.T) {
+	server := newContentServer(t, _testVersions)
+
+	type resolved struct {
+		VersionId  string
+		Dependency bool
+		RequiredBy []string
+	}
+	tests := []struct {
+		name         string
+		projectId    string
+		versionId    string
+		installed    []IndexEntry
+		want         []resolved
+		wantErr      error
+		wantErrText  string
+		wantIndexReq map[string][]string
+	}{
+		{
+			name:      "newest version with required dependencies",
+			projectId: "sodium-extra",
+			want: []resolved{
+				{VersionId: "sodium-extra-0.6"},
+				{VersionId: "sodium-0.6", Dependency: true, RequiredBy: []string{"sodium-extra", "iris"}},
+				{VersionId: "iris-1.8", Dependency: true, RequiredBy: []string{"sodium-extra"}},
+			},
+		},
+		{
+			name:      "pinned version",
+			projectId: "sodium",
+			versionId: "sodium-0.5",
+			want:      []resolved{{VersionId: "sodium-0.5"}},
+		},
+		{
+			name:        "version of another project",
+			projectId:   "iris",
+			versionId:   "sodium-0.5",
+			wantErrText: "does not belong to project iris",
+		},
+		{
+			name:      "installed dependency is kept and records the new dependent",
+			projectId: "iris",
+			installed: []IndexEntry{{ProjectId: "sodium", FileName: "sodium-0.6.jar", Dependency: true, RequiredBy: []string{"sodium-extra"}}},
+			want:      []resolved{{VersionId: "iris-1.8"}},
+			wantIndexReq: map[string][]string{
+				"sodium": {"sodium-extra", "iris"},
+			},
+		},
+		{
+			name:      "installed mod is resolved again when asked for directly",
+			projectId: "sodium",
+			installed: []IndexEntry{{ProjectId: "sodium", FileName: "sodium-0.5.jar"}},
+			want:      []resolved{{VersionId: "sodium-0.6"}},
+		},
+		{
+			name:      "no compatible version",
+			projectId: "unknown",
+			wantErr:   ErrorNoCompatibleVersion,
+		},
+		{
+			name:      "missing required dependency",
+			projectId: "broken",
+			wantErr:   ErrorNoCompatibleVersion,
+		},
+		{
+			name:      "incompatible with an installed mod",
+			projectId: "optifabric",
+			installed: []IndexEntry{{ProjectId: "sodium", FileName: "sodium-0.6.jar"}},
+			wantErr:   ErrorIncompatibleMod,
+		},
+		{
+			name:        "version without files",
+			projectId:   "empty",
+			wantErrText: "has no files",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			index := Index{Mods: slices.Clone(test.installed)}
+			r := &resolver{
+				options: Options{APIURL: server.URL, GameVersion: "1.21.1", Loader: minecraft.LoaderFabric},
+				index:   &index,
+			}
+
+			err := r.resolve(test.projectId, test.versionId, "", false)
+			switch {
+			case test.wantErr != nil:
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("resolve = %v, want %v", err, test.wantErr)
+				}
+				return
+			case test.wantErrText != "":
+				if err == nil || !strings.Contains(err.Error(), test.wantErrText) {
+					t.Fatalf("resolve = %v, want an error containing %q", err, test.wantErrText)
+				}
+				return
+			case err != nil:
+				t.Fatalf("resolve: %v", err)
+			}
+
+			var got []resolved
+			for _, mod := range r.resolved {
+				got = append(got, resolved{VersionId: mod.version.Id, Dependency: mod.dependency, RequiredBy: mod.requiredBy})
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("resolved =\n%+v\nwant\n%+v", got, test.want)
+			}
+			for projectId, want := range test.wantIndexReq {
+				if i := index.find(projectId); i < 0 || !reflect.DeepEqual(index.Mods[i].RequiredBy, want) {
+					t.Errorf("index entry for %s = %+v, want it required by %v", projectId, index.Mods, want)
+				}
+			}
+		})
+	}
+}
+
+func readTestModFiles(t *testing.T, gameDir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(filepath.Join(gameDir, "mods"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, entry := range entries {
+		if entry.Name() == _indexFileName {
+			continue
+		}
+		if entry.IsDir() {
+			t.Errorf("%s was left in mods/", entry.Name())
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(gameDir, "mods", entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[entry.Name()] = string(data)
+	}
+	return files
+}
+
+func TestInstallAndRemoveSharedDependency(t *testing.T) {
+	server := newContentServer(t, _testVersions)
+	options := Options{APIURL: server.URL, GameDirectory: t.TempDir(), GameVersion: "1.21.1", Loader: minecraft.LoaderFabric}
+
+	if _, err := InstallMod("sodium-extra", "", options, nil); err != nil {
+		t.Fatalf("InstallMod(sodium-extra): %v", err)
+	}
+	installed, err := InstallMod("iris", "", options, nil)
+	if err != nil {
+		t.Fatalf("InstallMod(iris): %v", err)
+	}
+	if len(installed) != 1 || installed[0].ProjectId != "iris" || installed[0].Dependency {
+		t.Errorf("InstallMod(iris) = %+v, want iris on its own", installed)
+	}
+
+	want := map[string]string{
+		"sodium-extra-0.6.jar": "sodium-extra-0.6 contents",
+		"iris-1.8.jar":         "iris-1.8 contents",
+		"sodium-0.6.jar":       "sodium-0.6 contents",
+	}
+	if files := readTestModFiles(t, options.GameDirectory); !reflect.DeepEqual(files, want) {
+		t.Errorf("mods = %v, want %v", files, want)
+	}
+
+	// sodium is still needed by iris after sodium-extra goes.
+	removed, err := RemoveMod("sodium-extra", options)
+	if err != nil {
+		t.Fatalf("RemoveMod(sodium-extra): %v", err)
+	}
+	if len(removed) != 1 {
+		t.Errorf("RemoveMod(sodium-extra) removed %+v, want only sodium-extra", removed)
+	}
+	if _, err := os.Stat(filepath.Join(options.GameDirectory, "mods", "sodium-0.6.jar")); err != nil {
+		t.Errorf("sodium was removed while iris needs it: %v", err)
+	}
+
+	removed, err = RemoveMod("iris", options)
+	if err != nil {
+		t.Fatalf("RemoveMod(iris): %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("RemoveMod(iris) removed %+v, want iris and sodium", removed)
+	}
+	if files := readTestModFiles(t, options.GameDirectory); len(files) != 0 {
+		t.Errorf("mods = %v after removing everything", files)
+	}
+}
+
+func TestInstallModRollback(t *testing.T) {
+	tests := []struct {
+		name    string
+		missing []string
+		setup   func(t *testing.T, modsDir string)
+	}{
+		{
+			name:    "failed download",
+			missing: []string{"sodium-0.6.jar"},
+		},
+		{
+			// Every file is already in place when the index cannot be written.
+			name: "failed index write",
+			setup: func(t *testing.T, modsDir string) {
+				if err := os.MkdirAll(filepath.Join(modsDir, _indexFileName+".tmp", "in-the-way"), 0755); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newContentServer(t, _testVersions, test.missing...)
+			options := Options{APIURL: server.URL, GameDirectory: t.TempDir(), GameVersion: "1.21.1", Loader: minecraft.LoaderFabric}
+			modsDir := filepath.Join(options.GameDirectory, "mods")
+
+			// A jar the user put there by hand has the same name as one of the downloads.
+			existing := map[string]string{"iris-1.8.jar": "the user's own build"}
+			os.MkdirAll(modsDir, 0755)
+			if err := os.WriteFile(filepath.Join(modsDir, "iris-1.8.jar"), []byte(existing["iris-1.8.jar"]), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if test.setup != nil {
+				test.setup(t, modsDir)
+			}
+
+			if _, err := InstallMod("sodium-extra", "", options, nil); err == nil {
+				t.Fatal("InstallMod succeeded")
+			}
+			// Only the directory the test put in the way may be left over.
+			os.RemoveAll(filepath.Join(modsDir, _indexFileName+".tmp"))
+			if files := readTestModFiles(t, options.GameDirectory); !reflect.DeepEqual(files, existing) {
+				t.Errorf("mods = %v after a failed install, want %v", files, existing)
+			}
+			if index, _ := ReadIndex(options.GameDirectory); len(index.Mods) != 0 {
+				t.Errorf("index = %+v after a failed install", index.Mods)
+			}
+		})
+	}
+}
+
+func TestInstallModReplacesExistingFile(t *testing.T) {
+	server := newContentServer(t, _testVersions)
+	options := Options{APIURL: server.URL, GameDirectory: t.TempDir(), GameVersion: "1.21.1", Loader: minecraft.LoaderFabric}
+
+	if _, err := InstallMod("sodium", "sodium-0.5", options, nil); err != nil {
+		t.Fatalf("InstallMod(sodium-0.5): %v", err)
+	}
+	if _, err := InstallMod("sodium", "", options, nil); err != nil {
+		t.Fatalf("InstallMod(sodium): %v", err)
+	}
+
+	want := map[string]string{"sodium-0.6.jar": "sodium-0.6 contents"}
+	if files := readTestModFiles(t, options.GameDirectory); !reflect.DeepEqual(files, want) {
+		t.Errorf("mods = %v, want the old version replaced by %v", files, want)
+	}
+	index, err := ReadIndex(options.GameDirectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Mods) != 1 || index.Mods[0].VersionId != "sodium-0.6" {
+		t.Errorf("index = %+v, want only sodium-0.6", index.Mods)
+	}
+}
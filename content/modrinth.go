@@ -0,0 +1,108 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"urodstvo-launcher/minecraft"
+)
+
+const _apiURL = "https://api.modrinth.com/v2"
+
+func getJSON[T any](options Options, path string, query url.Values) (T, error) {
+	var result T
+
+	apiURL := options.APIURL
+	if apiURL == "" {
+		apiURL = _apiURL
+	}
+
+	reqURL := strings.TrimSuffix(apiURL, "/") + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest("GET", reqURL, nil)
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "urodstvo-launcher/"+minecraft.GetLibraryVersion())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("content api returned %s for %s", resp.Status, path)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, err
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, fmt.Errorf("failed to unmarshal JSON: %v", err)
+	}
+	return result, nil
+}
+
+func jsonList(values []string) string {
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+func SearchMods(query, gameVersion, loader string, options Options) (SearchResult, error) {
+	facets := [][]string{{"project_type:mod"}}
+	if gameVersion != "" {
+		facets = append(facets, []string{"versions:" + gameVersion})
+	}
+	if loader != "" && loader != minecraft.LoaderVanilla {
+		var categories []string
+		for _, l := range minecraft.GetCompatibleModLoaders(loader) {
+			categories = append(categories, "categories:"+l)
+		}
+		facets = append(facets, categories)
+	}
+
+	facetsJSON, err := json.Marshal(facets)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	values := url.Values{}
+	values.Set("query", query)
+	values.Set("facets", string(facetsJSON))
+	if options.Limit > 0 {
+		values.Set("limit", strconv.Itoa(options.Limit))
+	}
+	if options.Offset > 0 {
+		values.Set("offset", strconv.Itoa(options.Offset))
+	}
+
+	return getJSON[SearchResult](options, "/search", values)
+}
+
+func GetVersion(versionId string, options Options) (Version, error) {
+	return getJSON[Version](options, "/version/"+url.PathEscape(versionId), nil)
+}
+
+func GetProjectVersions(projectId string, options Options) ([]Version, error) {
+	values := url.Values{}
+	if options.GameVersion != "" {
+		values.Set("game_versions", jsonList([]string{options.GameVersion}))
+	}
+	if options.Loader != "" && options.Loader != minecraft.LoaderVanilla {
+		values.Set("loaders", jsonList(minecraft.GetCompatibleModLoaders(options.Loader)))
+	}
+
+	return getJSON[[]Version](options, "/project/"+url.PathEscape(projectId)+"/version", values)
+}
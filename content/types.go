@@ -0,0 +1,77 @@
+package content
+
+type Options struct {
+	APIURL        string `json:"apiUrl,omitempty"`
+	GameDirectory string `json:"gameDirectory"`
+	GameVersion   string `json:"gameVersion"`
+	Loader        string `json:"loader"`
+	Limit         int    `json:"limit,omitempty"`
+	Offset        int    `json:"offset,omitempty"`
+}
+
+type SearchHit struct {
+	ProjectId     string   `json:"project_id"`
+	Slug          string   `json:"slug"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	Author        string   `json:"author"`
+	Downloads     int      `json:"downloads"`
+	Follows       int      `json:"follows"`
+	IconURL       string   `json:"icon_url"`
+	Categories    []string `json:"categories"`
+	Versions      []string `json:"versions"`
+	LatestVersion string   `json:"latest_version"`
+	DateModified  string   `json:"date_modified"`
+	ClientSide    string   `json:"client_side"`
+	ServerSide    string   `json:"server_side"`
+}
+
+type SearchResult struct {
+	Hits      []SearchHit `json:"hits"`
+	Offset    int         `json:"offset"`
+	Limit     int         `json:"limit"`
+	TotalHits int         `json:"total_hits"`
+}
+
+type VersionFile struct {
+	Hashes   map[string]string `json:"hashes"`
+	Url      string            `json:"url"`
+	Filename string            `json:"filename"`
+	Primary  bool              `json:"primary"`
+	Size     int64             `json:"size"`
+}
+
+type VersionDependency struct {
+	VersionId      *string `json:"version_id"`
+	ProjectId      *string `json:"project_id"`
+	FileName       *string `json:"file_name"`
+	DependencyType string  `json:"dependency_type"`
+}
+
+type Version struct {
+	Id            string              `json:"id"`
+	ProjectId     string              `json:"project_id"`
+	Name          string              `json:"name"`
+	VersionNumber string              `json:"version_number"`
+	GameVersions  []string            `json:"game_versions"`
+	Loaders       []string            `json:"loaders"`
+	VersionType   string              `json:"version_type"`
+	DatePublished string              `json:"date_published"`
+	Files         []VersionFile       `json:"files"`
+	Dependencies  []VersionDependency `json:"dependencies"`
+}
+
+type IndexEntry struct {
+	ProjectId   string            `json:"projectId"`
+	VersionId   string            `json:"versionId"`
+	Version     string            `json:"version"`
+	FileName    string            `json:"fileName"`
+	Hashes      map[string]string `json:"hashes"`
+	Dependency  bool              `json:"dependency"`
+	RequiredBy  []string          `json:"requiredBy,omitempty"`
+	InstalledAt string            `json:"installedAt"`
+}
+
+type Index struct {
+	Mods []IndexEntry `json:"mods"`
+}
@@ -0,0 +1,332 @@
+package launcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"urodstvo-launcher/minecraft"
+)
+
+const (
+	AccountTypeMicrosoft = "msa"
+	AccountTypeFree      = "free"
+	AccountTypeYggdrasil = "yggdrasil"
+)
+
+var ErrorAccountNotFound = errors.New("account not found")
+
+func findAccount(c *launcherCache, id string) *LauncherAccount {
+	for i := range c.Accounts {
+		if c.Accounts[i].Id == id {
+			return &c.Accounts[i]
+		}
+	}
+	return nil
+}
+
+// decodeEventData accepts an event payload both as emitted in-process and after a JSON round-trip through the frontend.
+func decodeEventData[T any](data any) (T, error) {
+	if args, ok := data.([]any); ok && len(args) > 0 {
+		data = args[0]
+	}
+
+	switch value := data.(type) {
+	case *T:
+		if value != nil {
+			return *value, nil
+		}
+	case T:
+		return value, nil
+	}
+
+	var value T
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return value, err
+	}
+	err = json.Unmarshal(raw, &value)
+	return value, err
+}
+
+func decodeLoginResponse(data any) (minecraft.CompleteLoginResponse, error) {
+	resp, err := decodeEventData[minecraft.CompleteLoginResponse](data)
+	if err == nil && resp.ID == "" {
+		err = errors.New("login response has no profile id")
+	}
+	return resp, err
+}
+
+func applyLoginResponse(account *LauncherAccount, resp minecraft.CompleteLoginResponse) {
+	account.Id = resp.ID
+	account.Type = AccountTypeMicrosoft
+	account.Name = resp.Name
+	account.Skins = resp.Skins
+	account.Capes = resp.Capes
+	account.Demo = resp.Demo
+	account.Error = ""
+	account.ErrorMessage = ""
+	account.AccessToken = resp.AccessToken
+	if resp.RefreshToken != "" {
+		account.RefreshToken = resp.RefreshToken
+	}
+
+	obtainedAt, err := time.Parse(time.RFC3339, resp.ObtainedAt)
+	if err != nil {
+		obtainedAt = time.Now().UTC()
+	}
+	account.ObtainedAt = obtainedAt.Format(time.RFC3339)
+	account.ExpiresAt = ""
+	if resp.ExpiresIn > 0 {
+		account.ExpiresAt = obtainedAt.Add(time.Duration(resp.ExpiresIn) * time.Second).Format(time.RFC3339)
+	}
+}
+
+// saveMicrosoftAccount stores a completed login, replacing any account for the same profile, and selects it.
+func (l *LauncherService) saveMicrosoftAccount(resp minecraft.CompleteLoginResponse) error {
+	if resp.ID == "" {
+		return errors.New("login response has no profile id")
+	}
+
+	return l.state.update(func(c *launcherCache) error {
+		account := findAccount(c, resp.ID)
+		if account == nil {
+			c.Accounts = append(c.Accounts, LauncherAccount{})
+			account = &c.Accounts[len(c.Accounts)-1]
+		}
+
+		applyLoginResponse(account, resp)
+		c.SelectedAccount = account.Id
+		return nil
+	})
+}
+
+func applyYggdrasilLogin(account *LauncherAccount, login minecraft.YggdrasilLogin) {
+	account.Id = login.Profile.ID
+	account.Type = AccountTypeYggdrasil
+	account.Name = login.Profile.Name
+	account.AuthServer = login.APIRoot
+	account.ClientToken = login.ClientToken
+	account.AccessToken = login.AccessToken
+	account.Error = ""
+	account.ErrorMessage = ""
+	account.ObtainedAt = time.Now().UTC().Format(time.RFC3339)
+}
+
+func (l *LauncherService) saveYggdrasilAccount(login minecraft.YggdrasilLogin) error {
+	if login.Profile.ID == "" {
+		return errors.New("login response has no profile id")
+	}
+
+	return l.state.update(func(c *launcherCache) error {
+		account := findAccount(c, login.Profile.ID)
+		if account == nil {
+			c.Accounts = append(c.Accounts, LauncherAccount{})
+			account = &c.Accounts[len(c.Accounts)-1]
+		}
+
+		applyYggdrasilLogin(account, login)
+		c.SelectedAccount = account.Id
+		return nil
+	})
+}
+
+// TokenRefresher exchanges a refresh token for a new login; the auth service provides it.
+type TokenRefresher func(refreshToken string) (*minecraft.CompleteLoginResponse, error)
+
+// AccountErrorReloginRequired marks an account whose refresh token no longer works.
+const AccountErrorReloginRequired = "ReloginRequired"
+
+// _tokenRefreshMargin refreshes tokens this long before they expire, so a session never starts on a token about to die.
+const _tokenRefreshMargin = 30 * time.Minute
+
+func (l *LauncherService) SetTokenRefresher(refresher TokenRefresher) {
+	l.refresher = refresher
+}
+
+func accountNeedsRefresh(account LauncherAccount, now time.Time) bool {
+	// Yggdrasil servers do not announce expiry, so their tokens are validated instead.
+	if account.Type == AccountTypeYggdrasil {
+		return account.AccessToken != ""
+	}
+	if account.Type != AccountTypeMicrosoft || account.RefreshToken == "" {
+		return false
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, account.ExpiresAt)
+	if err != nil {
+		return true
+	}
+	return now.Add(_tokenRefreshMargin).After(expiresAt)
+}
+
+// refreshAccount refreshes one account when its token is near expiry, or always when force is set.
+// Refreshes are serialised because each one rotates the refresh token.
+func (l *LauncherService) refreshAccount(id string, force bool) error {
+	l.refreshMu.Lock()
+	defer l.refreshMu.Unlock()
+
+	cache, _ := l.snapshot()
+	account := findAccount(&cache, id)
+	if account == nil {
+		return ErrorAccountNotFound
+	}
+	if !force && !accountNeedsRefresh(*account, time.Now()) {
+		return nil
+	}
+
+	var apply func(stored *LauncherAccount)
+	var refreshErr error
+	switch {
+	case account.Type == AccountTypeMicrosoft && account.RefreshToken != "" && l.refresher != nil:
+		var resp *minecraft.CompleteLoginResponse
+		resp, refreshErr = l.refresher(account.RefreshToken)
+		apply = func(stored *LauncherAccount) {
+			applyLoginResponse(stored, *resp)
+		}
+	case account.Type == AccountTypeYggdrasil && account.AccessToken != "":
+		var login *minecraft.YggdrasilLogin
+		login, refreshErr = refreshYggdrasilAccount(*account, force)
+		if login == nil && refreshErr == nil {
+			return nil
+		}
+		apply = func(stored *LauncherAccount) {
+			applyYggdrasilLogin(stored, *login)
+		}
+	default:
+		return nil
+	}
+
+	if refreshErr != nil && !needsRelogin(refreshErr) {
+		// An outage says nothing about the saved sign-in, so keep the tokens and try again next time.
+		if !force && tokenStillValid(*account, time.Now()) {
+			fmt.Println("Failed to refresh account, using the current token:", refreshErr)
+			return nil
+		}
+		return fmt.Errorf("failed to refresh account %s: %w", account.Name, refreshErr)
+	}
+
+	err := l.state.update(func(c *launcherCache) error {
+		stored := findAccount(c, id)
+		if stored == nil {
+			return ErrorAccountNotFound
+		}
+
+		if refreshErr != nil {
+			stored.Error = AccountErrorReloginRequired
+			stored.ErrorMessage = refreshErr.Error()
+			return nil
+		}
+
+		apply(stored)
+		stored.Id = id
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if refreshErr != nil {
+		return fmt.Errorf("account %s needs to sign in again: %w", account.Name, refreshErr)
+	}
+	return nil
+}
+
+// needsRelogin reports whether a refresh failed because the server rejected the saved sign-in, as opposed
+// to a network error, a timeout or a server error.
+func needsRelogin(err error) bool {
+	if errors.Is(err, minecraft.ErrInvalidRefreshToken) {
+		return true
+	}
+
+	var yggdrasilErr *minecraft.YggdrasilError
+	if errors.As(err, &yggdrasilErr) {
+		return yggdrasilErr.Status == http.StatusUnauthorized || yggdrasilErr.Status == http.StatusForbidden
+	}
+	return false
+}
+
+// tokenStillValid reports whether the game can still start with the account's current token.
+func tokenStillValid(account LauncherAccount, now time.Time) bool {
+	if account.AccessToken == "" {
+		return false
+	}
+	// Yggdrasil tokens carry no expiry; the server has the final say when joining.
+	if account.Type == AccountTypeYggdrasil {
+		return true
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, account.ExpiresAt)
+	return err == nil && now.Before(expiresAt)
+}
+
+// refreshYggdrasilAccount returns nil without an error when the current token is still valid.
+func refreshYggdrasilAccount(account LauncherAccount, force bool) (*minecraft.YggdrasilLogin, error) {
+	if !force {
+		valid, err := minecraft.YggdrasilValidate(account.AuthServer, account.AccessToken, account.ClientToken)
+		if err != nil {
+			return nil, err
+		}
+		if valid {
+			return nil, nil
+		}
+	}
+
+	profile := &minecraft.YggdrasilProfile{ID: account.Id, Name: account.Name}
+	resp, err := minecraft.YggdrasilRefresh(account.AuthServer, account.AccessToken, account.ClientToken, profile)
+	if err != nil {
+		return nil, err
+	}
+	return &minecraft.YggdrasilLogin{
+		APIRoot: account.AuthServer,
+		AccessToken: resp.AccessToken,
+		ClientToken: resp.ClientToken,
+		Profile: *resp.SelectedProfile,
+	}, nil
+}
+
+func (l *LauncherService) refreshSelectedAccount() error {
+	cache, _ := l.snapshot()
+	if cache.SelectedAccount == "" {
+		return nil
+	}
+	return l.refreshAccount(cache.SelectedAccount, false)
+}
+
+func (l *LauncherService) refreshAccounts() {
+	cache, _ := l.snapshot()
+	for _, account := range cache.Accounts {
+		if !accountNeedsRefresh(account, time.Now()) {
+			continue
+		}
+		if err := l.refreshAccount(account.Id, false); err != nil {
+			fmt.Println("Failed to refresh account:", err)
+		}
+	}
+}
+
+// RefreshAccount forces a token refresh for the account, e.g. after the user retries a failed one.
+func (l *LauncherService) RefreshAccount(id string) error {
+	return l.refreshAccount(id, true)
+}
+
+// prepareAuthlibInjector downloads authlib-injector and prefetches the server metadata for Yggdrasil accounts.
+func (l *LauncherService) prepareAuthlibInjector(options *minecraft.MinecraftOptions) error {
+	if options.YggdrasilAPIURL == "" {
+		return nil
+	}
+
+	path, err := minecraft.InstallAuthlibInjector(GetDataDirectory(), l.settings().AuthlibInjectorURL)
+	if err != nil {
+		return err
+	}
+	options.AuthlibInjectorPath = path
+
+	// Without prefetched metadata authlib-injector fetches it itself, so a failure here is not fatal.
+	if metadata, err := minecraft.GetYggdrasilMetadata(options.YggdrasilAPIURL); err == nil {
+		options.YggdrasilMetadata = string(metadata)
+	}
+	return nil
+}
@@ -1,13 +1,26 @@
 package launcher
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"time"
 
 	"urodstvo-launcher/minecraft"
 )
 
-var _launcherCachePath = "launcherCache.json"
+const _launcherCacheFile = "launcherCache.json"
+
+// _plaintextTokenRegex matches the token fields older versions wrote into the cache. It works on the raw
+// text, so corrupt copies that no longer parse are covered too.
+var _plaintextTokenRegex = regexp.MustCompile(`"(access_token|refresh_token)"(\s*):(\s*)"[^"]*"`)
+
+func getLauncherCachePath() string {
+	return filepath.Join(GetDataDirectory(), _launcherCacheFile)
+}
 
 type LauncherAccount struct {
 	Id            string                `json:"id"`
@@ -17,9 +30,16 @@ type LauncherAccount struct {
 	Capes         []minecraft.MinecraftProfileCape `json:"capes"`
 	Error         string                `json:"error"`
 	ErrorMessage  string                `json:"errorMessage"`
+	Demo          bool                  `json:"demo,omitempty"` // the account does not own the game and can only play the demo
+	AuthServer    string                `json:"authServer,omitempty"` // Yggdrasil API root for third-party accounts
+	ClientToken   string                `json:"clientToken,omitempty"`
 
-	AccessToken  	string `json:"access_token"`
-	RefreshToken 	string `json:"refresh_token"`
+	// Tokens live in a credential store; the cache only keeps the Credentials reference.
+	Credentials  	string `json:"credentials,omitempty"`
+	AccessToken  	string `json:"access_token,omitempty"`
+	RefreshToken 	string `json:"refresh_token,omitempty"`
+	ExpiresAt    	string `json:"expires_at,omitempty"`
+	ObtainedAt   	string `json:"obtained_at,omitempty"`
 }
 
 type LauncherSettings struct {
@@ -33,19 +53,120 @@ type LauncherSettings struct {
 	ShowOnlyInstalled bool `json:"showOnlyInstalled"`
 	ResolutionWidth int `json:"resolutionWidth,omitempty"`
 	ResolutionHeight int `json:"resolutionHeight,omitempty"`	
+	CurseForgeAPIURL string `json:"curseForgeAPIURL,omitempty"`
+	CurseForgeAPIKey string `json:"curseForgeAPIKey,omitempty"`
+	ModrinthAPIURL string `json:"modrinthAPIURL,omitempty"`
+	SyncVanillaProfiles bool `json:"syncVanillaProfiles,omitempty"`
+	AuthlibInjectorURL string `json:"authlibInjectorURL,omitempty"`
+	MinecraftServicesURL string `json:"minecraftServicesURL,omitempty"`
+}
+
+type LauncherInstance struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	GameDirectory string `json:"gameDirectory"`
+	VersionId string `json:"versionId,omitempty"`
+	MinecraftVersion string `json:"minecraftVersion"`
+	Loader string `json:"loader"`
+	LoaderVersion string `json:"loaderVersion,omitempty"`
+	AllocatedRAM int `json:"allocatedRAM,omitempty"`
+	JVMArguments string `json:"jvmArguments,omitempty"`
+	JavaPath string `json:"javaPath,omitempty"`
+	ResolutionWidth int `json:"resolutionWidth,omitempty"`
+	ResolutionHeight int `json:"resolutionHeight,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	LastPlayedAt string `json:"lastPlayedAt,omitempty"`
+	VanillaProfileId string `json:"vanillaProfileId,omitempty"`
 }
 
 type launcherCache struct {
+	SchemaVersion int `json:"schemaVersion"`
 	LastPlayedVersion *minecraft.MinecraftVersionInfo `json:"last_played_version"`
 	Settings *LauncherSettings `json:"settings"`
+	SelectedInstance string `json:"selectedInstance,omitempty"`
+	Instances []LauncherInstance `json:"instances,omitempty"`
 	SelectedAccount string `json:"selectedAccount,omitempty"`
 	Accounts []LauncherAccount `json:"accounts,omitempty"`
+
+	// recovery describes what happened when the cache could not be loaded cleanly; it is shown to the user once.
+	recovery string
+	credentials *credentialVault
 }
 
+// _launcherCacheMigrations[i] upgrades a cache from schema version i to i+1.
+var _launcherCacheMigrations = []func(data map[string]any) error{
+	// 0 -> 1: files written before schemaVersion existed could have an empty game directory.
+	func(data map[string]any) error {
+		settings, ok := data["settings"].(map[string]any)
+		if !ok {
+			return nil
+		}
+		if dir, _ := settings["gameDirectory"].(string); dir == "" {
+			settings["gameDirectory"] = minecraft.GetMinecraftDirectory()
+		}
+		return nil
+	},
+	// 1 -> 2: free accounts used random ids; give them the offline UUID the server derives from the name.
+	func(data map[string]any) error {
+		accounts, ok := data["accounts"].([]any)
+		if !ok {
+			return nil
+		}
+
+		renamed := make(map[string]string)
+		seen := make(map[string]bool)
+		kept := make([]any, 0, len(accounts))
+		for _, entry := range accounts {
+			account, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+			if accountType, _ := account["type"].(string); accountType == AccountTypeFree {
+				oldId, _ := account["id"].(string)
+				name, _ := account["name"].(string)
+				newId := minecraft.GetOfflineUUID(name)
+				renamed[oldId] = newId
+				account["id"] = newId
+
+				// Free accounts with the same name are the same player now.
+				if seen[newId] {
+					continue
+				}
+				seen[newId] = true
+			}
+			kept = append(kept, account)
+		}
+		data["accounts"] = kept
+
+		if selected, _ := data["selectedAccount"].(string); renamed[selected] != "" {
+			data["selectedAccount"] = renamed[selected]
+		}
+		return nil
+	},
+}
+
+var _launcherCacheSchemaVersion = len(_launcherCacheMigrations)
+
 func newCache() *launcherCache {
-	EnsureFileExists(_launcherCachePath)
-	l := &launcherCache{}
-	l.Load()
+	os.MkdirAll(GetDataDirectory(), 0755)
+	migrateLegacyFile(_launcherCacheFile)
+	migrateLegacyFile(".env")
+
+	l := &launcherCache{credentials: newCredentialVault(GetDataDirectory())}
+	if err := l.Load(); err != nil {
+		fmt.Println("Failed to load launcher cache:", err)
+	}
+
+	tokensStored := true
+	if l.credentials.loadAccounts(l.Accounts) {
+		if err := l.Save(); err != nil {
+			fmt.Println("Failed to move account tokens to the credential store:", err)
+			tokensStored = false
+		}
+	}
+	if tokensStored {
+		scrubPlaintextTokens()
+	}
 
 	if l.Settings == nil {
 		l.Settings = &LauncherSettings{
@@ -61,23 +182,128 @@ func newCache() *launcherCache {
 		l.Save()
 	}
 
-
-	
 	return l
 }
 
 func (c *launcherCache) Save() error {
-	data, err := json.MarshalIndent(c, "", "  ")
+	if c.SchemaVersion < _launcherCacheSchemaVersion {
+		c.SchemaVersion = _launcherCacheSchemaVersion
+	}
+
+	persisted := *c
+	var credentialsErr error
+	if c.credentials != nil {
+		persisted.Accounts, credentialsErr = c.credentials.storeAccounts(c.Accounts)
+	}
+
+	data, err := json.MarshalIndent(&persisted, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(_launcherCachePath, data, 0644)
+
+	path := getLauncherCachePath()
+	if current, err := os.ReadFile(path); err == nil && json.Valid(current) {
+		if err := writeFileAtomic(path+".bak", current, 0600); err != nil {
+			fmt.Println("Failed to back up launcher cache:", err)
+		}
+	}
+
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return err
+	}
+	return credentialsErr
+}
+
+// scrubPlaintextTokens blanks the tokens in the copies of the cache that backups, recovery and the move
+// to the data directory leave behind. It runs once the tokens are safe in the credential store.
+func scrubPlaintextTokens() {
+	path := getLauncherCachePath()
+	paths := []string{path + ".bak"}
+	if corrupt, err := filepath.Glob(path + ".corrupt-*"); err == nil {
+		paths = append(paths, corrupt...)
+	}
+	if legacy, err := filepath.Abs(_launcherCacheFile); err == nil {
+		if target, _ := filepath.Abs(path); target != legacy {
+			paths = append(paths, legacy, legacy+".migrated")
+		}
+	}
+
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		scrubbed := _plaintextTokenRegex.ReplaceAll(data, []byte(`"$1"$2:$3""`))
+		if bytes.Equal(scrubbed, data) {
+			continue
+		}
+		if err := writeFileAtomic(p, scrubbed, 0600); err != nil {
+			fmt.Println("Failed to remove tokens from", p, err)
+		}
+	}
+}
+
+func decodeLauncherCache(data []byte, c *launcherCache) error {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		return fmt.Errorf("launcher cache is empty")
+	}
+
+	version := 0
+	if v, ok := raw["schemaVersion"].(float64); ok {
+		version = int(v)
+	}
+	if version > _launcherCacheSchemaVersion {
+		c.recovery = fmt.Sprintf("Launcher settings were saved by a newer version (schema %d); unknown settings may be ignored.", version)
+	}
+
+	for ; version < _launcherCacheSchemaVersion; version++ {
+		if err := _launcherCacheMigrations[version](raw); err != nil {
+			return fmt.Errorf("failed to migrate launcher cache from schema %d: %w", version, err)
+		}
+	}
+	raw["schemaVersion"] = version
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(migrated, c)
 }
 
+// Load reads the cache, falling back to the backup when the main file is missing data or corrupt.
 func (c *launcherCache) Load() error {
-	data, err := os.ReadFile(_launcherCachePath)
+	path := getLauncherCachePath()
+
+	data, err := os.ReadFile(path)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
 		return err
 	}
-	return json.Unmarshal(data, c)
-}
\ No newline at end of file
+
+	loadErr := decodeLauncherCache(data, c)
+	if loadErr == nil {
+		return nil
+	}
+
+	corruptPath := fmt.Sprintf("%s.corrupt-%d", path, time.Now().Unix())
+	if len(data) > 0 {
+		os.WriteFile(corruptPath, data, 0600)
+	}
+
+	*c = launcherCache{credentials: c.credentials}
+	backup, err := os.ReadFile(path + ".bak")
+	if err == nil && decodeLauncherCache(backup, c) == nil {
+		c.recovery = fmt.Sprintf("Launcher settings were damaged (%v) and have been restored from the last backup.", loadErr)
+		return writeFileAtomic(path, backup, 0600)
+	}
+
+	*c = launcherCache{credentials: c.credentials}
+	c.recovery = fmt.Sprintf("Launcher settings were damaged (%v) and no usable backup was found, so defaults were restored.", loadErr)
+	return loadErr
+}
@@ -0,0 +1,458 @@
+package launcher
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	_credentialsFile = "credentials.json"
+	_credentialsKeyFile = ".credentials.key"
+	_credentialsFileVersion = 1
+	_credentialPassphraseEnv = "URODSTVO_LAUNCHER_PASSPHRASE"
+)
+
+var ErrorCredentialNotFound = errors.New("credential not found")
+
+// CredentialStore keeps secrets outside of launcherCache.json.
+type CredentialStore interface {
+	Name() string
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+type credentialFile struct {
+	Version int `json:"version"`
+	KeySource string `json:"keySource"`
+	Salt []byte `json:"salt"`
+	Entries map[string][]byte `json:"entries"`
+}
+
+// fileCredentialStore is the portable fallback: an AES-GCM encrypted file whose key is derived with scrypt
+// from a user passphrase or, without one, from a per-machine secret.
+type fileCredentialStore struct {
+	mu sync.Mutex
+	path string
+	secret []byte
+	keySource string
+
+	salt []byte
+	key []byte
+}
+
+func newFileCredentialStore(dataDir string) (*fileCredentialStore, error) {
+	store := &fileCredentialStore{path: filepath.Join(dataDir, _credentialsFile)}
+
+	if passphrase := os.Getenv(_credentialPassphraseEnv); passphrase != "" {
+		store.secret = []byte(passphrase)
+		store.keySource = "passphrase"
+		return store, nil
+	}
+
+	secret, err := getMachineSecret(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	store.secret = secret
+	store.keySource = "machine"
+	return store, nil
+}
+
+func (s *fileCredentialStore) Name() string {
+	return "file"
+}
+
+func (s *fileCredentialStore) deriveKey(salt []byte) ([]byte, error) {
+	if s.key != nil && bytes.Equal(s.salt, salt) {
+		return s.key, nil
+	}
+
+	key, err := scrypt.Key(s.secret, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	s.salt = salt
+	s.key = key
+	return key, nil
+}
+
+func (s *fileCredentialStore) read() (credentialFile, error) {
+	var file credentialFile
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s.newFile()
+	}
+	if err != nil {
+		return file, err
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, fmt.Errorf("credential store is damaged: %w", err)
+	}
+	if file.Entries == nil {
+		file.Entries = map[string][]byte{}
+	}
+	return file, nil
+}
+
+func (s *fileCredentialStore) newFile() (credentialFile, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return credentialFile{}, err
+	}
+	return credentialFile{
+		Version: _credentialsFileVersion,
+		KeySource: s.keySource,
+		Salt: salt,
+		Entries: map[string][]byte{},
+	}, nil
+}
+
+func (s *fileCredentialStore) write(file credentialFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data, 0600)
+}
+
+func (s *fileCredentialStore) aead(file credentialFile) (cipher.AEAD, error) {
+	key, err := s.deriveKey(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *fileCredentialStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	sealed, ok := file.Entries[key]
+	if !ok {
+		return "", ErrorCredentialNotFound
+	}
+	if file.KeySource != s.keySource {
+		return "", fmt.Errorf("credentials were encrypted with a %s key", file.KeySource)
+	}
+
+	aead, err := s.aead(file)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("stored credential is truncated")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return "", errors.New("stored credential could not be decrypted")
+	}
+	return string(plaintext), nil
+}
+
+func (s *fileCredentialStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.read()
+	if err != nil {
+		return err
+	}
+	// Entries sealed with another key can never be opened again, so start over rather than mixing keys.
+	if file.KeySource != s.keySource {
+		if file, err = s.newFile(); err != nil {
+			return err
+		}
+	}
+
+	aead, err := s.aead(file)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	file.Entries[key] = aead.Seal(nonce, nonce, []byte(value), []byte(key))
+	return s.write(file)
+}
+
+func (s *fileCredentialStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Entries[key]; !ok {
+		return ErrorCredentialNotFound
+	}
+
+	delete(file.Entries, key)
+	return s.write(file)
+}
+
+// getMachineSecret identifies this machine and user, so a copied credentials file is useless elsewhere.
+// Machines without a readable id get a random secret kept next to the store.
+func getMachineSecret(dataDir string) ([]byte, error) {
+	id := getMachineId()
+	if id == "" {
+		keyPath := filepath.Join(dataDir, _credentialsKeyFile)
+		key, err := os.ReadFile(keyPath)
+		if err != nil || len(key) == 0 {
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+			if err := writeFileAtomic(keyPath, key, 0600); err != nil {
+				return nil, err
+			}
+		}
+		id = string(key)
+	}
+
+	account := ""
+	if current, err := user.Current(); err == nil {
+		account = current.Uid
+	}
+
+	sum := sha256.Sum256([]byte("urodstvo-launcher\x00" + id + "\x00" + account))
+	return sum[:], nil
+}
+
+func getMachineId() string {
+	switch runtime.GOOS {
+	case "windows":
+		cmd := exec.Command("reg", "query", `HKLM\SOFTWARE\Microsoft\Cryptography`, "/v", "MachineGuid")
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			HideWindow: true,
+			CreationFlags: 0x08000000,
+		}
+		output, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 3 && fields[0] == "MachineGuid" {
+				return fields[2]
+			}
+		}
+	case "darwin":
+		output, err := exec.Command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output()
+		if err != nil {
+			return ""
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if _, value, found := strings.Cut(line, `"IOPlatformUUID" = `); found {
+				return strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+	default:
+		for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+			if data, err := os.ReadFile(path); err == nil {
+				if id := strings.TrimSpace(string(data)); id != "" {
+					return id
+				}
+			}
+		}
+	}
+	return ""
+}
+
+type accountCredentials struct {
+	AccessToken string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// credentialVault moves account tokens between the cache and the credential stores. Accounts in the cache only
+// carry a "<store>:<key>" reference; the first store that accepts a write is used for it.
+type credentialVault struct {
+	mu sync.Mutex
+	stores []CredentialStore
+
+	// written remembers what each reference last held, so saving the cache does not rewrite every secret.
+	written map[string]string
+}
+
+func newCredentialVault(dataDir string) *credentialVault {
+	vault := &credentialVault{written: map[string]string{}}
+
+	if store, err := newSecretServiceStore(); err == nil {
+		vault.stores = append(vault.stores, store)
+	}
+	if store, err := newFileCredentialStore(dataDir); err == nil {
+		vault.stores = append(vault.stores, store)
+	} else {
+		fmt.Println("Encrypted credential file is unavailable:", err)
+	}
+
+	return vault
+}
+
+func (v *credentialVault) getStore(name string) CredentialStore {
+	for _, store := range v.stores {
+		if store.Name() == name {
+			return store
+		}
+	}
+	return nil
+}
+
+func credentialKey(account LauncherAccount) string {
+	return "account-" + account.Id
+}
+
+// loadAccounts fills in tokens from the stores. It reports whether any account still had plaintext tokens
+// in the cache and needs to be saved again to move them out.
+func (v *credentialVault) loadAccounts(accounts []LauncherAccount) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	migrate := false
+	for i := range accounts {
+		account := &accounts[i]
+		if account.Credentials == "" {
+			if account.AccessToken != "" || account.RefreshToken != "" {
+				migrate = true
+			}
+			continue
+		}
+
+		name, key, _ := strings.Cut(account.Credentials, ":")
+		store := v.getStore(name)
+		if store == nil {
+			account.Error = AccountErrorReloginRequired
+			account.ErrorMessage = fmt.Sprintf("credential store %q is not available", name)
+			continue
+		}
+
+		value, err := store.Get(key)
+		if err == nil {
+			var credentials accountCredentials
+			err = json.Unmarshal([]byte(value), &credentials)
+			account.AccessToken = credentials.AccessToken
+			account.RefreshToken = credentials.RefreshToken
+		}
+		if err != nil {
+			account.Error = AccountErrorReloginRequired
+			account.ErrorMessage = err.Error()
+			continue
+		}
+		v.written[account.Credentials] = value
+	}
+	return migrate
+}
+
+// storeAccounts writes the accounts' tokens to the stores, records the references on accounts and returns
+// copies that are safe to persist. Secrets of accounts that are gone are deleted.
+func (v *credentialVault) storeAccounts(accounts []LauncherAccount) ([]LauncherAccount, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var errs []error
+	persisted := make([]LauncherAccount, len(accounts))
+	current := map[string]bool{}
+
+	for i := range accounts {
+		account := &accounts[i]
+
+		if account.AccessToken != "" || account.RefreshToken != "" {
+			data, err := json.Marshal(accountCredentials{
+				AccessToken: account.AccessToken,
+				RefreshToken: account.RefreshToken,
+			})
+			if err != nil {
+				errs = append(errs, err)
+			} else if err := v.write(account, string(data)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if account.Credentials != "" {
+			current[account.Credentials] = true
+		}
+
+		persisted[i] = *account
+		persisted[i].AccessToken = ""
+		persisted[i].RefreshToken = ""
+	}
+
+	for ref := range v.written {
+		if current[ref] {
+			continue
+		}
+		name, key, _ := strings.Cut(ref, ":")
+		if store := v.getStore(name); store != nil {
+			if err := store.Delete(key); err != nil && !errors.Is(err, ErrorCredentialNotFound) {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		delete(v.written, ref)
+	}
+
+	return persisted, errors.Join(errs...)
+}
+
+func (v *credentialVault) write(account *LauncherAccount, value string) error {
+	if account.Credentials != "" && v.written[account.Credentials] == value {
+		return nil
+	}
+
+	key := credentialKey(*account)
+	var errs []error
+	for _, store := range v.stores {
+		if err := store.Set(key, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", store.Name(), err))
+			continue
+		}
+
+		ref := store.Name() + ":" + key
+		// A secret that moved to another store must not linger in the old one.
+		if account.Credentials != "" && account.Credentials != ref {
+			name, oldKey, _ := strings.Cut(account.Credentials, ":")
+			if old := v.getStore(name); old != nil {
+				old.Delete(oldKey)
+			}
+			delete(v.written, account.Credentials)
+		}
+
+		account.Credentials = ref
+		v.written[ref] = value
+		return nil
+	}
+
+	if len(errs) == 0 {
+		return errors.New("no credential store is available")
+	}
+	return fmt.Errorf("failed to store credentials for %s: %w", account.Name, errors.Join(errs...))
+}
@@ -0,0 +1,68 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const _dataDirectoryEnv = "URODSTVO_LAUNCHER_DATA_DIR"
+
+var _dataDirectory string
+
+// SetDataDirectory overrides where launcher state is kept, e.g. for portable installs.
+func SetDataDirectory(dir string) {
+	_dataDirectory = dir
+}
+
+func GetDataDirectory() string {
+	if _dataDirectory != "" {
+		return _dataDirectory
+	}
+	if dir := os.Getenv(_dataDirectoryEnv); dir != "" {
+		return dir
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(configDir, "urodstvo-launcher")
+}
+
+// migrateLegacyFile moves a file that older versions kept in the working directory into the data directory.
+func migrateLegacyFile(name string) {
+	dataDir := GetDataDirectory()
+	target := filepath.Join(dataDir, name)
+	if _, err := os.Stat(target); err == nil {
+		return
+	}
+
+	legacyPath, err := filepath.Abs(name)
+	if err != nil {
+		return
+	}
+	if absTarget, _ := filepath.Abs(target); absTarget == legacyPath {
+		return
+	}
+	if info, err := os.Stat(legacyPath); err != nil || info.Size() == 0 {
+		return
+	}
+
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		fmt.Println("Failed to create data directory:", err)
+		return
+	}
+
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		fmt.Println("Failed to migrate", name, err)
+		return
+	}
+	if err := os.WriteFile(target, data, 0600); err != nil {
+		fmt.Println("Failed to migrate", name, err)
+		return
+	}
+
+	os.Rename(legacyPath, legacyPath+".migrated")
+}
@@ -3,6 +3,7 @@ package launcher
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -25,6 +26,40 @@ func EnsureFileExists(path string) error {
 	return err
 }
 
+// writeFileAtomic writes to a temporary file, syncs it and renames it over path so readers never see a partial file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
 func LoadCacheToMinecraftOptions(cache launcherCache, mc *minecraft.MinecraftOptions) {
 	if cache.Settings != nil {
 		settings := cache.Settings
@@ -43,6 +78,16 @@ func LoadCacheToMinecraftOptions(cache launcherCache, mc *minecraft.MinecraftOpt
 			mc.Uuid = selected.Id 
 			mc.Username = selected.Name
 			mc.Token = selected.AccessToken
+			mc.UserType = minecraft.UserTypeMSA
+			mc.Demo = selected.Demo
+			if selected.Type == AccountTypeFree {
+				mc.Token = minecraft.OfflineAccessToken
+				mc.UserType = minecraft.UserTypeLegacy
+			}
+			if selected.Type == AccountTypeYggdrasil {
+				mc.UserType = minecraft.UserTypeMojang
+				mc.YggdrasilAPIURL = selected.AuthServer
+			}
 		}
 
 		if settings.ResolutionWidth > 0 {
@@ -68,4 +113,40 @@ func LoadCacheToMinecraftOptions(cache launcherCache, mc *minecraft.MinecraftOpt
 			mc.JvmArguments = jvmArgs
 		}
 	}
-}
\ No newline at end of file
+}
+
+func LoadInstanceToMinecraftOptions(cache launcherCache, instance LauncherInstance, mc *minecraft.MinecraftOptions) {
+	LoadCacheToMinecraftOptions(cache, mc)
+
+	mc.InstallDirectory = mc.GameDirectory
+	mc.GameDirectory = instance.GameDirectory
+	mc.ExecutablePath = instance.JavaPath
+
+	if instance.ResolutionWidth > 0 && instance.ResolutionHeight > 0 {
+		mc.CustomResolution = true
+		mc.ResolutionWidth = strconv.Itoa(instance.ResolutionWidth)
+		mc.ResolutionHeight = strconv.Itoa(instance.ResolutionHeight)
+	}
+
+	if instance.AllocatedRAM == 0 && instance.JVMArguments == "" {
+		return
+	}
+
+	var jvmArgs []string
+
+	ram := instance.AllocatedRAM
+	if ram == 0 && cache.Settings != nil {
+		ram = cache.Settings.AllocatedRAM
+	}
+	if ram > 0 {
+		jvmArgs = append(jvmArgs, fmt.Sprintf("-Xmx%vM", ram))
+	}
+
+	if instance.JVMArguments != "" {
+		jvmArgs = append(jvmArgs, strings.Fields(instance.JVMArguments)...)
+	} else if cache.Settings != nil && cache.Settings.JVMArguments != "" {
+		jvmArgs = append(jvmArgs, strings.Fields(cache.Settings.JVMArguments)...)
+	}
+
+	mc.JvmArguments = jvmArgs
+}
@@ -0,0 +1,354 @@
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"urodstvo-launcher/minecraft"
+
+	"github.com/google/uuid"
+)
+
+var ErrorInstanceNotFound = errors.New("instance not found")
+
+func findInstance(c *launcherCache, id string) *LauncherInstance {
+	for i := range c.Instances {
+		if c.Instances[i].Id == id {
+			return &c.Instances[i]
+		}
+	}
+	return nil
+}
+
+func (l *LauncherService) getInstance(id string) (LauncherInstance, bool) {
+	cache, _ := l.snapshot()
+	if instance := findInstance(&cache, id); instance != nil {
+		return *instance, true
+	}
+	return LauncherInstance{}, false
+}
+
+// gameDirectory is the selected instance's directory, or the shared install root when no instance is selected.
+func (l *LauncherService) gameDirectory() string {
+	cache, options := l.snapshot()
+	if instance := findInstance(&cache, cache.SelectedInstance); instance != nil {
+		return instance.GameDirectory
+	}
+	return options.GameDirectory
+}
+
+func (l *LauncherService) instanceOptions(instance LauncherInstance) minecraft.MinecraftOptions {
+	cache, mc := l.snapshot()
+	LoadInstanceToMinecraftOptions(cache, instance, &mc)
+	return mc
+}
+
+func (l *LauncherService) newInstance(name string) (LauncherInstance, error) {
+	instance := l.makeInstance(name)
+	return instance, os.MkdirAll(instance.GameDirectory, 0755)
+}
+
+// makeInstance fills in a new instance without creating its directory, for callers that point it elsewhere.
+func (l *LauncherService) makeInstance(name string) LauncherInstance {
+	id := uuid.New().String()
+	instance := LauncherInstance{
+		Id: id,
+		Name: strings.TrimSpace(name),
+		GameDirectory: filepath.Join(l.installDirectory(), "instances", id),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if instance.Name == "" {
+		instance.Name = "New Instance"
+	}
+	return instance
+}
+
+// addInstance pins the instance to an installed version and stores it.
+func (l *LauncherService) addInstance(instance LauncherInstance, versionId string) error {
+	if versionId != "" {
+		info, err := minecraft.GetVersionLoader(versionId, l.installDirectory())
+		if err != nil {
+			return err
+		}
+		instance.VersionId = info.VersionId
+		instance.MinecraftVersion = info.MinecraftVersion
+		instance.Loader = info.Loader
+		instance.LoaderVersion = info.LoaderVersion
+	}
+
+	return l.state.update(func(c *launcherCache) error {
+		c.Instances = append(c.Instances, instance)
+		return nil
+	})
+}
+
+func (l *LauncherService) GetInstances() []LauncherInstance {
+	cache, _ := l.snapshot()
+	if cache.Instances == nil {
+		return []LauncherInstance{}
+	}
+	return cache.Instances
+}
+
+func (l *LauncherService) GetSelectedInstance() string {
+	cache, _ := l.snapshot()
+	return cache.SelectedInstance
+}
+
+func (l *LauncherService) CreateInstance(instance LauncherInstance) (LauncherInstance, error) {
+	created, err := l.newInstance(instance.Name)
+	if err != nil {
+		return LauncherInstance{}, err
+	}
+
+	instance.Id = created.Id
+	instance.Name = created.Name
+	instance.CreatedAt = created.CreatedAt
+	if instance.GameDirectory == "" {
+		instance.GameDirectory = created.GameDirectory
+	} else if err := os.MkdirAll(instance.GameDirectory, 0755); err != nil {
+		return LauncherInstance{}, err
+	}
+	if instance.Loader == "" {
+		instance.Loader = minecraft.LoaderVanilla
+	}
+	instance.VersionId = ""
+
+	return instance, l.state.update(func(c *launcherCache) error {
+		c.Instances = append(c.Instances, instance)
+		return nil
+	})
+}
+
+func (l *LauncherService) UpdateInstance(instance LauncherInstance) error {
+	err := l.state.update(func(c *launcherCache) error {
+		existing := findInstance(c, instance.Id)
+		if existing == nil {
+			return ErrorInstanceNotFound
+		}
+
+		if instance.GameDirectory == "" {
+			instance.GameDirectory = existing.GameDirectory
+		}
+		if instance.MinecraftVersion != existing.MinecraftVersion || instance.Loader != existing.Loader || instance.LoaderVersion != existing.LoaderVersion {
+			instance.VersionId = ""
+		}
+		instance.CreatedAt = existing.CreatedAt
+		instance.LastPlayedAt = existing.LastPlayedAt
+		instance.VanillaProfileId = existing.VanillaProfileId
+
+		*existing = instance
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return l.syncVanillaProfile(instance)
+}
+
+func (l *LauncherService) DeleteInstance(id string, deleteFiles bool) error {
+	instance, found := l.getInstance(id)
+	if !found {
+		return ErrorInstanceNotFound
+	}
+
+	installDir := l.installDirectory()
+	if deleteFiles && filepath.Clean(instance.GameDirectory) != filepath.Clean(installDir) {
+		if err := os.RemoveAll(instance.GameDirectory); err != nil {
+			return err
+		}
+	}
+
+	if l.settings().SyncVanillaProfiles && instance.VanillaProfileId != "" {
+		err := minecraft.DeleteVanillaLauncherProfile(installDir, instance.VanillaProfileId)
+		if err != nil && !errors.Is(err, minecraft.ErrorVanillaProfileNotFound) {
+			return err
+		}
+	}
+
+	return l.state.update(func(c *launcherCache) error {
+		c.Instances = slices.DeleteFunc(c.Instances, func(i LauncherInstance) bool {
+			return i.Id == id
+		})
+		if c.SelectedInstance == id {
+			c.SelectedInstance = ""
+		}
+		return nil
+	})
+}
+
+func (l *LauncherService) SelectInstance(id string) error {
+	return l.state.update(func(c *launcherCache) error {
+		if id != "" && findInstance(c, id) == nil {
+			return ErrorInstanceNotFound
+		}
+
+		c.SelectedInstance = id
+		return nil
+	})
+}
+
+func (l *LauncherService) StartInstance(id string, safeMode bool) LaunchResult {
+	instance, found := l.getInstance(id)
+	if !found {
+		return LaunchResult{Error: ErrorInstanceNotFound.Error()}
+	}
+
+	if err := l.refreshSelectedAccount(); err != nil {
+		return LaunchResult{Error: err.Error()}
+	}
+
+	options := l.instanceOptions(instance)
+	if options.Uuid == "" {
+		return LaunchResult{Error: "no account selected"}
+	}
+
+	if err := os.MkdirAll(options.GameDirectory, 0755); err != nil {
+		return LaunchResult{Error: err.Error()}
+	}
+
+	versionId := instance.VersionId
+	if versionId == "" {
+		installed, err := minecraft.InstallLoader(instance.Loader, instance.MinecraftVersion, instance.LoaderVersion, options, l.installCallback())
+		if err != nil {
+			return LaunchResult{Error: err.Error()}
+		}
+		versionId = installed
+	} else if err := minecraft.InstallMinecraftVersion(versionId, options, l.installCallback()); err != nil {
+		return LaunchResult{Error: err.Error()}
+	}
+
+	lastPlayedAt := time.Now().UTC().Format(time.RFC3339)
+	l.state.update(func(c *launcherCache) error {
+		if existing := findInstance(c, id); existing != nil {
+			existing.VersionId = versionId
+			existing.LastPlayedAt = lastPlayedAt
+			instance = *existing
+		}
+		c.SelectedInstance = id
+		return nil
+	})
+	if err := l.syncVanillaProfile(instance); err != nil {
+		fmt.Println("Failed to sync launcher profile:", err)
+	}
+
+	return l.launch(versionId, options, safeMode)
+}
+
+func (l *LauncherService) ExportInstance(id, path string, options minecraft.InstanceExportOptions) error {
+	instance, found := l.getInstance(id)
+	if !found {
+		return ErrorInstanceNotFound
+	}
+
+	manifest := minecraft.InstanceManifest{
+		Name: instance.Name,
+		VersionId: instance.VersionId,
+		MinecraftVersion: instance.MinecraftVersion,
+		Loader: instance.Loader,
+		LoaderVersion: instance.LoaderVersion,
+		AllocatedRAM: instance.AllocatedRAM,
+		JVMArguments: instance.JVMArguments,
+		ResolutionWidth: instance.ResolutionWidth,
+		ResolutionHeight: instance.ResolutionHeight,
+	}
+
+	return minecraft.ExportInstanceArchive(instance.GameDirectory, path, manifest, options)
+}
+
+func (l *LauncherService) ImportInstance(path string) (LauncherInstance, error) {
+	instance, err := l.newInstance("")
+	if err != nil {
+		return LauncherInstance{}, err
+	}
+
+	manifest, err := minecraft.ExtractInstanceArchive(path, instance.GameDirectory)
+	if err != nil {
+		os.RemoveAll(instance.GameDirectory)
+		return LauncherInstance{}, err
+	}
+
+	if manifest.Name != "" {
+		instance.Name = manifest.Name
+	}
+	instance.MinecraftVersion = manifest.MinecraftVersion
+	instance.Loader = manifest.Loader
+	instance.LoaderVersion = manifest.LoaderVersion
+	instance.AllocatedRAM = manifest.AllocatedRAM
+	instance.JVMArguments = manifest.JVMArguments
+	instance.ResolutionWidth = manifest.ResolutionWidth
+	instance.ResolutionHeight = manifest.ResolutionHeight
+
+	options := l.instanceOptions(instance)
+	var versionId string
+	if instance.MinecraftVersion != "" {
+		versionId, err = minecraft.InstallLoader(instance.Loader, instance.MinecraftVersion, instance.LoaderVersion, options, l.installCallback())
+	} else {
+		versionId = manifest.VersionId
+		err = minecraft.InstallMinecraftVersion(versionId, options, l.installCallback())
+	}
+	if err != nil {
+		os.RemoveAll(instance.GameDirectory)
+		return LauncherInstance{}, err
+	}
+
+	if err := l.addInstance(instance, versionId); err != nil {
+		os.RemoveAll(instance.GameDirectory)
+		return LauncherInstance{}, err
+	}
+	instance, _ = l.getInstance(instance.Id)
+	return instance, nil
+}
+
+func (l *LauncherService) FindMultiMCInstances() ([]minecraft.MultiMCInstance, error) {
+	return minecraft.FindMultiMCInstances()
+}
+
+// ImportMultiMCInstance imports a MultiMC/Prism instance folder, copying its game directory or linking to it.
+func (l *LauncherService) ImportMultiMCInstance(path string, link bool) (LauncherInstance, error) {
+	source, err := minecraft.ReadMultiMCInstance(path)
+	if err != nil {
+		return LauncherInstance{}, err
+	}
+
+	instance, err := l.newInstance(source.Name)
+	if err != nil {
+		return LauncherInstance{}, err
+	}
+
+	instance.MinecraftVersion = source.MinecraftVersion
+	instance.Loader = source.Loader
+	instance.LoaderVersion = source.LoaderVersion
+	instance.AllocatedRAM = source.MaxMemory
+	instance.JVMArguments = source.JVMArguments
+	instance.JavaPath = source.JavaPath
+	instance.ResolutionWidth = source.WindowWidth
+	instance.ResolutionHeight = source.WindowHeight
+
+	if source.GameDirectory != "" {
+		if err := minecraft.CopyGameDirectory(source.GameDirectory, instance.GameDirectory, link); err != nil {
+			os.RemoveAll(instance.GameDirectory)
+			return LauncherInstance{}, err
+		}
+	}
+
+	options := l.instanceOptions(instance)
+	versionId, err := minecraft.InstallLoader(instance.Loader, instance.MinecraftVersion, instance.LoaderVersion, options, l.installCallback())
+	if err != nil {
+		os.RemoveAll(instance.GameDirectory)
+		return LauncherInstance{}, err
+	}
+
+	if err := l.addInstance(instance, versionId); err != nil {
+		os.RemoveAll(instance.GameDirectory)
+		return LauncherInstance{}, err
+	}
+	instance, _ = l.getInstance(instance.Id)
+	return instance, nil
+}
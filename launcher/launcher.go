@@ -2,22 +2,27 @@ package launcher
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"sync"
 	"syscall"
 	"time"
 
+	"urodstvo-launcher/content"
 	"urodstvo-launcher/minecraft"
 
-	"github.com/google/uuid"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/wailsapp/wails/v3/pkg/application"
 	"github.com/wailsapp/wails/v3/pkg/events"
 )
 
 type LauncherService struct {
-	M minecraft.MinecraftOptions
-	cache *launcherCache
+	state *launcherState
+
+	refresher TokenRefresher
+	refreshMu sync.Mutex
 
 	window *application.WebviewWindow
 	app *application.App
@@ -32,9 +37,21 @@ func NewLauncherService() *LauncherService {
 	cache := newCache()
 	LoadCacheToMinecraftOptions(*cache, &mc)
 
+	gameDirectories := []string{mc.GameDirectory}
+	for _, instance := range cache.Instances {
+		gameDirectories = append(gameDirectories, instance.GameDirectory)
+	}
+	for _, dir := range gameDirectories {
+		if err := minecraft.RestoreSafeMode(dir); err != nil {
+			fmt.Println("Failed to restore mods after safe mode:", err)
+		}
+	}
+
 	return &LauncherService{
-		M: mc,
-		cache: cache,
+		state: newLauncherState(cache, minecraft.MinecraftOptions{
+			LauncherVersion: mc.LauncherVersion,
+			LauncherName: mc.LauncherName,
+		}),
 	}
 }
 
@@ -90,25 +107,62 @@ func (l *LauncherService) SetApp(app *application.App) {
 		event.Cancel()
 	})
 
+	l.state.setChanged(func(state LauncherState) {
+		app.EmitEvent("state:changed", state)
+	})
+
+	// The frontend may not be listening yet, so the message stays until it asks for it.
+	if recovery := l.state.getRecovery(); recovery != "" {
+		app.EmitEvent("launcher:cache:recovered", recovery)
+	}
+
+	go l.refreshAccounts()
+
 	app.OnEvent("auth:microsoft:failed", func(e *application.CustomEvent) {
 		fmt.Println("Auth failed:", e.ToJSON())
 	})
 	
 	app.OnEvent("auth:microsoft:success", func(e *application.CustomEvent) {
-		fmt.Println("Auth success:", e.Data)
+		resp, err := decodeLoginResponse(e.Data)
+		if err != nil {
+			fmt.Println("Auth success with unreadable payload:", err)
+			return
+		}
+		if err := l.saveMicrosoftAccount(resp); err != nil {
+			fmt.Println("Failed to save Microsoft account:", err)
+		}
+	})
+
+	app.OnEvent("auth:yggdrasil:success", func(e *application.CustomEvent) {
+		login, err := decodeEventData[minecraft.YggdrasilLogin](e.Data)
+		if err == nil {
+			err = l.saveYggdrasilAccount(login)
+		}
+		if err != nil {
+			fmt.Println("Failed to save Yggdrasil account:", err)
+		}
 	})
 
 	app.OnEvent("auth:free:success", func(e *application.CustomEvent) {
 		username := e.Data.([]any)[0].(string)
+		if err := minecraft.ValidateUsername(username); err != nil {
+			fmt.Println("Rejected offline account:", err)
+			return
+		}
+
 		acc := LauncherAccount{
 			Name: username,
-			Id: uuid.New().String(),
-			Type: "free",
+			Id: minecraft.GetOfflineUUID(username),
+			Type: AccountTypeFree,
 		}
 
-		l.cache.Accounts = append(l.cache.Accounts, acc)
-		l.SelectAccount(acc.Id)
-		l.cache.Save()
+		l.state.update(func(c *launcherCache) error {
+			if findAccount(c, acc.Id) == nil {
+				c.Accounts = append(c.Accounts, acc)
+			}
+			c.SelectedAccount = acc.Id
+			return nil
+		})
 	})
 }
 
@@ -120,8 +174,32 @@ func (l *LauncherService) GetMinecraftVersions() ([]minecraft.MinecraftVersionIn
 	return minecraft.GetVersionList()
 }
 
+// GetState returns a snapshot of everything the frontend mirrors; "state:changed" carries the same shape.
+func (l *LauncherService) GetState() LauncherState {
+	return l.state.publicState()
+}
+
+func (l *LauncherService) snapshot() (launcherCache, minecraft.MinecraftOptions) {
+	return l.state.snapshot()
+}
+
+// installDirectory is the shared root for versions, libraries, assets and runtimes.
+func (l *LauncherService) installDirectory() string {
+	_, options := l.snapshot()
+	return options.GameDirectory
+}
+
+func (l *LauncherService) settings() LauncherSettings {
+	cache, _ := l.snapshot()
+	if cache.Settings == nil {
+		return LauncherSettings{}
+	}
+	return *cache.Settings
+}
+
 func (l *LauncherService) GetLastPlayedVersion() *minecraft.MinecraftVersionInfo {
- if l.cache.LastPlayedVersion == nil {
+ cache, _ := l.snapshot()
+ if cache.LastPlayedVersion == nil {
 	var found *minecraft.MinecraftVersionInfo
 	v, _ := minecraft.GetLatestVersion()
 	l, _ := minecraft.GetVersionList()
@@ -133,17 +211,17 @@ func (l *LauncherService) GetLastPlayedVersion() *minecraft.MinecraftVersionInfo
 	}
 	return found
  }
- return l.cache.LastPlayedVersion
+ return cache.LastPlayedVersion
 }
 
 func (l *LauncherService) GetInstalledVersion() ([]minecraft.MinecraftVersionInfo, error) {
-	return minecraft.GetInstalledVersions(l.M.GameDirectory)
+	return minecraft.GetInstalledVersions(l.installDirectory())
 }
 
 func (l *LauncherService) OpenMinecraftDirectory() {
 	dir := minecraft.GetMinecraftDirectory()
-	if l.M.GameDirectory != "" {
-		dir = l.M.GameDirectory
+	if gameDir := l.installDirectory(); gameDir != "" {
+		dir = gameDir
 	}
 
 	var cmd *exec.Cmd
@@ -170,30 +248,126 @@ func (l *LauncherService) GetTotalRAM() (uint64, error) {
 }
 
 func (l *LauncherService) SaveLauncherSettings(settings LauncherSettings) error  {
-	l.cache.Settings = &settings
-	err := l.cache.Save()
-	if err != nil {
-		return err
-	}
-
-	LoadCacheToMinecraftOptions(*l.cache, &l.M)
+	return l.state.update(func(c *launcherCache) error {
+		c.Settings = &settings
+		return nil
+	})
+}
 
-	return nil
+// GetCacheRecoveryMessage reports, once, whether the settings file had to be restored at startup. The frontend
+// calls it after subscribing to launcher:cache:recovered, so a message emitted before that is not lost.
+func (l *LauncherService) GetCacheRecoveryMessage() string {
+	return l.state.takeRecovery()
 }
 
 func (l *LauncherService) GetLauncherSettings() LauncherSettings {
-	return *l.cache.Settings
+	return l.settings()
+}
+
+type LaunchResult struct {
+	Started bool `json:"started"`
+	Error string `json:"error,omitempty"`
+	Issues []minecraft.ModIssue `json:"issues,omitempty"`
+}
+
+func (l *LauncherService) StartMinecraft(version minecraft.MinecraftVersionInfo) LaunchResult {
+	return l.startMinecraft(version, false)
 }
 
-func (l *LauncherService) StartMinecraft(version minecraft.MinecraftVersionInfo) bool {
-	l.cache.LastPlayedVersion = &version	
-	l.cache.Save()
+// StartMinecraftSafeMode launches with every mod disabled for a single run.
+func (l *LauncherService) StartMinecraftSafeMode(version minecraft.MinecraftVersionInfo) LaunchResult {
+	return l.startMinecraft(version, true)
+}
+
+func (l *LauncherService) startMinecraft(version minecraft.MinecraftVersionInfo, safeMode bool) LaunchResult {
+	l.state.update(func(c *launcherCache) error {
+		c.LastPlayedVersion = &version
+		return nil
+	})
+
+	if err := l.refreshSelectedAccount(); err != nil {
+		return LaunchResult{Error: err.Error()}
+	}
+
+	_, options := l.snapshot()
+	if options.Uuid == "" {
+		return LaunchResult{Error: "no account selected"}
+	}
+
+	err := minecraft.InstallMinecraftVersion(version.Id, options, l.installCallback())
+	if err != nil {
+		return LaunchResult{Error: err.Error()}
+	}
+
+	return l.launch(version.Id, options, safeMode)
+}
+
+func (l *LauncherService) launch(versionId string, options minecraft.MinecraftOptions, safeMode bool) LaunchResult {
+	installDir := options.InstallDirectory
+	if installDir == "" {
+		installDir = options.GameDirectory
+	}
+
+	if err := l.prepareAuthlibInjector(&options); err != nil {
+		return LaunchResult{Error: err.Error()}
+	}
+
+	var issues []minecraft.ModIssue
+	var err error
+	if safeMode {
+		if _, err := minecraft.EnableSafeMode(options.GameDirectory); err != nil {
+			return LaunchResult{Error: err.Error()}
+		}
+	} else {
+		issues, err = minecraft.CheckMods(options.GameDirectory, versionId, installDir)
+		if err != nil {
+			return LaunchResult{Error: err.Error()}
+		}
+		if minecraft.HasBlockingModIssues(issues) {
+			return LaunchResult{Issues: issues}
+		}
+	}
+
+	restore := func() {
+		if !safeMode {
+			return
+		}
+		if err := minecraft.RestoreSafeMode(options.GameDirectory); err != nil {
+			fmt.Println("Failed to restore mods after safe mode:", err)
+		}
+	}
+
+	command, err := minecraft.GetMinecraftCommand(versionId, options)
+	if err != nil {
+		restore()
+		return LaunchResult{Error: err.Error(), Issues: issues}
+	}
 
-	if l.M.Uuid == "" {
-		return false
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = options.GameDirectory
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow: true,
+		CreationFlags: 0x08000000,
 	}
+	if err := cmd.Start(); err != nil {
+		restore()
+		return LaunchResult{Error: err.Error(), Issues: issues}
+	}
+
+	go func(){
+		l.window.Close()
+		cmd.Wait()
+		restore()
+		l.window.Show()
+		time.Sleep(50 * time.Millisecond)
+		l.window.Focus()
+	}()
 
-	callback := &minecraft.Callback{
+	return LaunchResult{Started: true, Issues: issues}
+}
+
+func (l *LauncherService) installCallback() *minecraft.Callback {
+	return &minecraft.Callback{
 		Progress: func(message string) {
 			l.app.EmitEvent("install:progress", message)
 			// fmt.Println("[Progress] - ", message)
@@ -207,32 +381,143 @@ func (l *LauncherService) StartMinecraft(version minecraft.MinecraftVersionInfo)
 			// fmt.Println("[Max] - ", message)
 		},
 	}
+}
+
+func (l *LauncherService) ImportCurseForgeModpack(path string) (*minecraft.CurseForgeInstallReport, error) {
+	settings := l.settings()
+	options := minecraft.CurseForgeInstallOptions{
+		APIURL: settings.CurseForgeAPIURL,
+		APIKey: settings.CurseForgeAPIKey,
+	}
 
-	err := minecraft.InstallMinecraftVersion(version.Id, l.M, callback)
+	instance, err := l.newInstance(filepath.Base(path))
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	command, err := minecraft.GetMinecraftCommand(version.Id, l.M)
+	report, err := minecraft.InstallCurseForgeModpack(path, l.installDirectory(), instance.GameDirectory, options, l.installCallback())
 	if err != nil {
-		return false
+		os.RemoveAll(instance.GameDirectory)
+		return nil, err
 	}
 
-	go func(){
-		cmd := exec.Command(command[0], command[1:]...)
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			HideWindow: true,
-			CreationFlags: 0x08000000,
-		}
-		cmd.Start()
-		l.window.Close()
-		cmd.Wait()
-		l.window.Show()
-		time.Sleep(50 * time.Millisecond)
-		l.window.Focus()
-	}()
+	if report.Name != "" {
+		instance.Name = report.Name
+	}
+	if err := l.addInstance(instance, report.LaunchVersion); err != nil {
+		os.RemoveAll(instance.GameDirectory)
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ImportMrpack installs a Modrinth modpack into a new instance of its own.
+func (l *LauncherService) ImportMrpack(path string, options minecraft.MrpackInstallOptions) (LauncherInstance, error) {
+	info, err := minecraft.GetMrpackInformation(path)
+	if err != nil {
+		return LauncherInstance{}, err
+	}
+
+	name := info.Name
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	instance, err := l.newInstance(name)
+	if err != nil {
+		return LauncherInstance{}, err
+	}
+
+	launchVersion, err := minecraft.InstallMrpack(path, l.installDirectory(), instance.GameDirectory, options, l.installCallback())
+	if err != nil {
+		os.RemoveAll(instance.GameDirectory)
+		return LauncherInstance{}, err
+	}
+
+	if err := l.addInstance(instance, launchVersion); err != nil {
+		os.RemoveAll(instance.GameDirectory)
+		return LauncherInstance{}, err
+	}
+
+	created, _ := l.getInstance(instance.Id)
+	return created, nil
+}
+
+func (l *LauncherService) ExportMrpack(versionId string, options minecraft.MrpackExportOptions) error {
+	if options.MinecraftDirectory == "" {
+		options.MinecraftDirectory = l.installDirectory()
+	}
+
+	return minecraft.ExportMrpack(l.gameDirectory(), versionId, options)
+}
+
+func (l *LauncherService) GetMods() ([]minecraft.ModInfo, error) {
+	return minecraft.ListMods(l.gameDirectory())
+}
+
+func (l *LauncherService) CheckModUpdates(versionId string) (*minecraft.ModUpdatePlan, error) {
+	options := minecraft.ModUpdateOptions{
+		APIURL: l.settings().ModrinthAPIURL,
+	}
+
+	return minecraft.CheckModUpdates(l.gameDirectory(), versionId, l.installDirectory(), options)
+}
+
+func (l *LauncherService) ApplyModUpdates(updates []minecraft.ModUpdate) error {
+	gameDir := l.gameDirectory()
+	updateIndex := func() error {
+		return content.ReplaceIndexedFiles(gameDir, updates)
+	}
+
+	return minecraft.ApplyModUpdates(gameDir, updates, updateIndex, l.installCallback())
+}
+
+func (l *LauncherService) contentOptions(versionId string) (content.Options, error) {
+	options := content.Options{
+		APIURL: l.settings().ModrinthAPIURL,
+		GameDirectory: l.gameDirectory(),
+	}
+	if versionId == "" {
+		return options, nil
+	}
+
+	loader, err := minecraft.GetVersionLoader(versionId, l.installDirectory())
+	if err != nil {
+		return options, err
+	}
+	options.GameVersion = loader.MinecraftVersion
+	options.Loader = loader.Loader
+
+	return options, nil
+}
+
+func (l *LauncherService) SearchMods(query, gameVersion, loader string, offset int) (content.SearchResult, error) {
+	options, _ := l.contentOptions("")
+	options.Offset = offset
+
+	return content.SearchMods(query, gameVersion, loader, options)
+}
+
+func (l *LauncherService) InstallMod(launchVersionId, projectId, versionId string) ([]content.IndexEntry, error) {
+	options, err := l.contentOptions(launchVersionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return content.InstallMod(projectId, versionId, options, l.installCallback())
+}
+
+func (l *LauncherService) RemoveMod(projectId string) ([]content.IndexEntry, error) {
+	options, _ := l.contentOptions("")
+	return content.RemoveMod(projectId, options)
+}
+
+func (l *LauncherService) GetContent(contentType, world string) ([]minecraft.ContentEntry, error) {
+	return minecraft.ListContent(l.gameDirectory(), contentType, world)
+}
 
-	return true
+func (l *LauncherService) SetContentEnabled(contentType, world string, names []string, enabled bool) error {
+	return minecraft.SetContentEnabled(l.gameDirectory(), contentType, world, names, enabled)
 }
 
 func (l *LauncherService) ChooseDirectory() (string, error) {
@@ -243,40 +528,42 @@ func (l *LauncherService) ChooseDirectory() (string, error) {
 }
 
 func (l *LauncherService) SelectAccount(id string) {
-	var selected LauncherAccount
-	for _, v := range l.cache.Accounts {
-		if v.Id == id {
-			selected = v
-			break
+	l.state.update(func(c *launcherCache) error {
+		var selected LauncherAccount
+		for _, v := range c.Accounts {
+			if v.Id == id {
+				selected = v
+				break
+			}
 		}
-	}
 
-	l.cache.SelectedAccount = selected.Id
-	l.M.Uuid = selected.Id 
-	l.M.Username = selected.Name
-	l.M.Token = selected.AccessToken
-	
-	l.cache.Save()
+		c.SelectedAccount = selected.Id
+		return nil
+	})
 }
 
 func (l *LauncherService) DeleteAccount(id string) {
-	if l.cache == nil {
-		return
-	}
-
-	newAccounts := make([]LauncherAccount, 0, len(l.cache.Accounts))
-	for _, acc := range l.cache.Accounts {
-		if acc.Id != id {
-			newAccounts = append(newAccounts, acc)
+	cache, _ := l.snapshot()
+	if account := findAccount(&cache, id); account != nil && account.Type == AccountTypeYggdrasil {
+		if err := minecraft.YggdrasilInvalidate(account.AuthServer, account.AccessToken, account.ClientToken); err != nil {
+			fmt.Println("Failed to invalidate Yggdrasil token:", err)
 		}
 	}
-	l.cache.Accounts = newAccounts
 
-	if l.cache.SelectedAccount == id {
-		l.cache.SelectedAccount = ""
-	}
+	l.state.update(func(c *launcherCache) error {
+		newAccounts := make([]LauncherAccount, 0, len(c.Accounts))
+		for _, acc := range c.Accounts {
+			if acc.Id != id {
+				newAccounts = append(newAccounts, acc)
+			}
+		}
+		c.Accounts = newAccounts
 
-	l.cache.Save()
+		if c.SelectedAccount == id {
+			c.SelectedAccount = ""
+		}
+		return nil
+	})
 }
 
 type AccountsInfo struct {
@@ -285,8 +572,9 @@ type AccountsInfo struct {
 }
 
 func (l *LauncherService) GetAccounts() AccountsInfo{
+	state := l.state.publicState()
 	return AccountsInfo{
-		SelectedAccount: l.cache.SelectedAccount,
-		Accounts: l.cache.Accounts,
+		SelectedAccount: state.SelectedAccount,
+		Accounts: state.Accounts,
 	}
 }
\ No newline at end of file
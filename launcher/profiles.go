@@ -0,0 +1,204 @@
+package launcher
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"urodstvo-launcher/minecraft"
+)
+
+var _xmxRegex = regexp.MustCompile(`^-Xmx(\d+)([gGmM])$`)
+
+func splitVanillaJavaArguments(args []string) (int, string) {
+	ram := 0
+	var rest []string
+	for _, arg := range args {
+		if match := _xmxRegex.FindStringSubmatch(arg); match != nil {
+			ram, _ = strconv.Atoi(match[1])
+			if strings.EqualFold(match[2], "g") {
+				ram *= 1024
+			}
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return ram, strings.Join(rest, " ")
+}
+
+func (l *LauncherService) vanillaProfileToInstance(profile minecraft.VanillaLauncherProfile, instance *LauncherInstance) error {
+	versionId, err := minecraft.GetVanillaLauncherProfileVersion(profile, func() map[string]string {
+		latest, _ := minecraft.GetLatestVersion()
+		return map[string]string{"release": latest.Release, "snapshot": latest.Snapshot}
+	})
+	if err != nil {
+		return err
+	}
+
+	instance.Name = profile.Name
+	instance.VanillaProfileId = profile.Id
+	installDir := l.installDirectory()
+	instance.GameDirectory = installDir
+	if profile.GameDirectory != nil {
+		instance.GameDirectory = *profile.GameDirectory
+	}
+	instance.JavaPath = ""
+	if profile.JavaExecutable != nil {
+		instance.JavaPath = *profile.JavaExecutable
+	}
+	instance.AllocatedRAM, instance.JVMArguments = splitVanillaJavaArguments(profile.JavaArguments)
+	instance.ResolutionWidth, instance.ResolutionHeight = 0, 0
+	if profile.CustomResolution != nil {
+		instance.ResolutionWidth = profile.CustomResolution.Width
+		instance.ResolutionHeight = profile.CustomResolution.Height
+	}
+
+	instance.VersionId = ""
+	instance.MinecraftVersion = versionId
+	instance.Loader = minecraft.LoaderVanilla
+	instance.LoaderVersion = ""
+	if info, err := minecraft.GetVersionLoader(versionId, installDir); err == nil {
+		instance.VersionId = info.VersionId
+		instance.MinecraftVersion = info.MinecraftVersion
+		instance.Loader = info.Loader
+		instance.LoaderVersion = info.LoaderVersion
+	}
+
+	return nil
+}
+
+func (l *LauncherService) instanceToVanillaProfile(instance LauncherInstance, profile *minecraft.VanillaLauncherProfile) {
+	versionId := instance.VersionId
+	if versionId == "" {
+		versionId = instance.MinecraftVersion
+	}
+
+	profile.Name = instance.Name
+	// Latest release and latest snapshot profiles follow new versions on their own; pinning them would stop that.
+	isLatest := profile.VersionType == "latest-release" || profile.VersionType == "latest-snapshot"
+	if !isLatest && (profile.VersionType != "custom" || profile.Version == nil || *profile.Version != versionId) {
+		profile.VersionType = "custom"
+		profile.Version = &versionId
+	}
+
+	profile.GameDirectory = nil
+	if filepath.Clean(instance.GameDirectory) != filepath.Clean(l.installDirectory()) {
+		gameDir := instance.GameDirectory
+		profile.GameDirectory = &gameDir
+	}
+
+	profile.JavaExecutable = nil
+	if instance.JavaPath != "" {
+		javaPath := instance.JavaPath
+		profile.JavaExecutable = &javaPath
+	}
+
+	profile.JavaArguments = nil
+	if instance.AllocatedRAM > 0 {
+		profile.JavaArguments = append(profile.JavaArguments, fmt.Sprintf("-Xmx%dM", instance.AllocatedRAM))
+	}
+	profile.JavaArguments = append(profile.JavaArguments, strings.Fields(instance.JVMArguments)...)
+
+	profile.CustomResolution = nil
+	if instance.ResolutionWidth > 0 && instance.ResolutionHeight > 0 {
+		profile.CustomResolution = &minecraft.Resolution{Width: instance.ResolutionWidth, Height: instance.ResolutionHeight}
+	}
+
+	if instance.LastPlayedAt != "" {
+		profile.LastUsed = instance.LastPlayedAt
+	}
+}
+
+func (l *LauncherService) GetVanillaProfiles() ([]minecraft.VanillaLauncherProfile, error) {
+	installDir := l.installDirectory()
+	if !minecraft.DoVanillaLauncherProfilesExist(installDir) {
+		return []minecraft.VanillaLauncherProfile{}, nil
+	}
+	return minecraft.LoadVanillaLauncherProfiles(installDir)
+}
+
+// ImportVanillaProfiles creates an instance for every vanilla profile and refreshes the ones imported before.
+func (l *LauncherService) ImportVanillaProfiles() ([]LauncherInstance, error) {
+	profiles, err := l.GetVanillaProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	cache, _ := l.snapshot()
+
+	imported := []LauncherInstance{}
+	for _, profile := range profiles {
+		var instance LauncherInstance
+		existing := slices.IndexFunc(cache.Instances, func(i LauncherInstance) bool {
+			return i.VanillaProfileId == profile.Id
+		})
+
+		if existing >= 0 {
+			instance = cache.Instances[existing]
+		} else {
+			// The instance uses the profile's game directory, so there is no instance directory to create.
+			instance = l.makeInstance(profile.Name)
+		}
+
+		if err := l.vanillaProfileToInstance(profile, &instance); err != nil {
+			return imported, err
+		}
+		imported = append(imported, instance)
+	}
+
+	return imported, l.state.update(func(c *launcherCache) error {
+		for _, instance := range imported {
+			if existing := findInstance(c, instance.Id); existing != nil {
+				*existing = instance
+			} else {
+				c.Instances = append(c.Instances, instance)
+			}
+		}
+		return nil
+	})
+}
+
+// syncVanillaProfile writes an instance back to launcher_profiles.json when syncing is enabled.
+func (l *LauncherService) syncVanillaProfile(instance LauncherInstance) error {
+	if !l.settings().SyncVanillaProfiles || instance.VanillaProfileId == "" {
+		return nil
+	}
+
+	installDir := l.installDirectory()
+	profile, err := minecraft.GetVanillaLauncherProfile(installDir, instance.VanillaProfileId)
+	if err != nil {
+		return err
+	}
+
+	l.instanceToVanillaProfile(instance, &profile)
+	return minecraft.UpdateVanillaLauncherProfile(installDir, instance.VanillaProfileId, profile)
+}
+
+// ExportInstanceToVanilla adds an instance to launcher_profiles.json and links it for syncing.
+func (l *LauncherService) ExportInstanceToVanilla(id string) error {
+	instance, found := l.getInstance(id)
+	if !found {
+		return ErrorInstanceNotFound
+	}
+	if instance.VanillaProfileId != "" {
+		return l.syncVanillaProfile(instance)
+	}
+
+	var profile minecraft.VanillaLauncherProfile
+	l.instanceToVanillaProfile(instance, &profile)
+
+	profileId, err := minecraft.CreateVanillaLauncherProfile(l.installDirectory(), profile)
+	if err != nil {
+		return err
+	}
+
+	return l.state.update(func(c *launcherCache) error {
+		if existing := findInstance(c, id); existing != nil {
+			existing.VanillaProfileId = profileId
+		}
+		return nil
+	})
+}
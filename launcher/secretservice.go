@@ -0,0 +1,215 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	_secretServiceName = "org.freedesktop.secrets"
+	_secretServicePath = dbus.ObjectPath("/org/freedesktop/secrets")
+	_secretServiceInterface = "org.freedesktop.Secret.Service"
+	_secretCollectionInterface = "org.freedesktop.Secret.Collection"
+	_secretItemInterface = "org.freedesktop.Secret.Item"
+	_secretPromptInterface = "org.freedesktop.Secret.Prompt"
+	_secretServiceApplication = "urodstvo-launcher"
+	// _secretServicePromptTimeout bounds how long a save waits for the user to answer a keyring prompt.
+	_secretServicePromptTimeout = 2 * time.Minute
+)
+
+// secretServiceSecret mirrors the (oayays) Secret struct of the Secret Service API.
+type secretServiceSecret struct {
+	Session dbus.ObjectPath
+	Parameters []byte
+	Value []byte
+	ContentType string
+}
+
+// secretServiceStore keeps credentials in the desktop keyring (GNOME Keyring, KWallet, KeePassXC) over D-Bus.
+type secretServiceStore struct {
+	conn *dbus.Conn
+	session dbus.ObjectPath
+}
+
+func newSecretServiceStore() (*secretServiceStore, error) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return nil, errors.New("secret service is not available on " + runtime.GOOS)
+	}
+
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	// Activating a missing service can block for the bus default timeout, so probe with a short one.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	err = conn.Object(_secretServiceName, _secretServicePath).
+		CallWithContext(ctx, _secretServiceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).
+		Store(&output, &session)
+	if err != nil {
+		return nil, fmt.Errorf("secret service is not available: %w", err)
+	}
+
+	return &secretServiceStore{conn: conn, session: session}, nil
+}
+
+func (s *secretServiceStore) Name() string {
+	return "keyring"
+}
+
+func (s *secretServiceStore) service() dbus.BusObject {
+	return s.conn.Object(_secretServiceName, _secretServicePath)
+}
+
+func secretServiceAttributes(key string) map[string]string {
+	return map[string]string{
+		"application": _secretServiceApplication,
+		"key": key,
+	}
+}
+
+// prompt shows a Secret Service prompt, e.g. to unlock the keyring, and waits for the user to answer it.
+func (s *secretServiceStore) prompt(path dbus.ObjectPath) error {
+	if path == "" || path == "/" {
+		return nil
+	}
+
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(_secretPromptInterface),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := s.conn.AddMatchSignal(match...); err != nil {
+		return err
+	}
+	defer s.conn.RemoveMatchSignal(match...)
+
+	signals := make(chan *dbus.Signal, 4)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	ctx, cancel := context.WithTimeout(context.Background(), _secretServicePromptTimeout)
+	defer cancel()
+
+	prompt := s.conn.Object(_secretServiceName, path)
+	if err := prompt.CallWithContext(ctx, _secretPromptInterface+".Prompt", 0, "").Err; err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case signal, ok := <-signals:
+			if !ok {
+				return errors.New("keyring prompt was interrupted")
+			}
+			if signal.Path != path || signal.Name != _secretPromptInterface+".Completed" {
+				continue
+			}
+			if len(signal.Body) > 0 {
+				if dismissed, _ := signal.Body[0].(bool); dismissed {
+					return errors.New("keyring prompt was dismissed")
+				}
+			}
+			return nil
+		case <-ctx.Done():
+			// Take the prompt down, so the user is not left answering a dialog nobody waits for.
+			prompt.Call(_secretPromptInterface+".Dismiss", dbus.FlagNoReplyExpected)
+			return errors.New("keyring prompt timed out")
+		}
+	}
+}
+
+func (s *secretServiceStore) unlock(path dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := s.service().Call(_secretServiceInterface+".Unlock", 0, []dbus.ObjectPath{path}).Store(&unlocked, &prompt)
+	if err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+func (s *secretServiceStore) findItem(key string) (dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.service().Call(_secretServiceInterface+".SearchItems", 0, secretServiceAttributes(key)).Store(&unlocked, &locked)
+	if err != nil {
+		return "", err
+	}
+
+	if len(unlocked) > 0 {
+		return unlocked[0], nil
+	}
+	if len(locked) > 0 {
+		return locked[0], s.unlock(locked[0])
+	}
+	return "", ErrorCredentialNotFound
+}
+
+func (s *secretServiceStore) Get(key string) (string, error) {
+	item, err := s.findItem(key)
+	if err != nil {
+		return "", err
+	}
+
+	var secret secretServiceSecret
+	err = s.conn.Object(_secretServiceName, item).Call(_secretItemInterface+".GetSecret", 0, s.session).Store(&secret)
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Value), nil
+}
+
+func (s *secretServiceStore) Set(key, value string) error {
+	var collection dbus.ObjectPath
+	if err := s.service().Call(_secretServiceInterface+".ReadAlias", 0, "default").Store(&collection); err != nil {
+		return err
+	}
+	if collection == "/" {
+		return errors.New("keyring has no default collection")
+	}
+	if err := s.unlock(collection); err != nil {
+		return err
+	}
+
+	properties := map[string]dbus.Variant{
+		_secretItemInterface + ".Label": dbus.MakeVariant("Urodstvo Launcher: " + key),
+		_secretItemInterface + ".Attributes": dbus.MakeVariant(secretServiceAttributes(key)),
+	}
+	secret := secretServiceSecret{
+		Session: s.session,
+		Parameters: []byte{},
+		Value: []byte(value),
+		ContentType: "text/plain; charset=utf8",
+	}
+
+	var item, prompt dbus.ObjectPath
+	err := s.conn.Object(_secretServiceName, collection).
+		Call(_secretCollectionInterface+".CreateItem", 0, properties, secret, true).
+		Store(&item, &prompt)
+	if err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+func (s *secretServiceStore) Delete(key string) error {
+	item, err := s.findItem(key)
+	if err != nil {
+		return err
+	}
+
+	var prompt dbus.ObjectPath
+	if err := s.conn.Object(_secretServiceName, item).Call(_secretItemInterface+".Delete", 0).Store(&prompt); err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
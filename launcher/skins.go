@@ -0,0 +1,97 @@
+package launcher
+
+import (
+	"errors"
+	"os"
+
+	"urodstvo-launcher/minecraft"
+)
+
+var ErrorAccountNotMicrosoft = errors.New("skins and capes can only be changed for Microsoft accounts")
+
+func (l *LauncherService) skinOptions() minecraft.SkinOptions {
+	return minecraft.SkinOptions{APIURL: l.settings().MinecraftServicesURL}
+}
+
+// changeProfile runs a profile change with a fresh token and stores the skins and capes it returns.
+func (l *LauncherService) changeProfile(accountId string, change func(accessToken string, options minecraft.SkinOptions) (*minecraft.MinecraftProfileResponse, error)) (LauncherAccount, error) {
+	if err := l.refreshAccount(accountId, false); err != nil {
+		return LauncherAccount{}, err
+	}
+
+	profile, err := l.sendProfileChange(accountId, change)
+	if errors.Is(err, minecraft.ErrAccessTokenExpired) {
+		// The token was revoked before it expired; get a new one and try once more.
+		if err := l.refreshAccount(accountId, true); err != nil {
+			return LauncherAccount{}, err
+		}
+		profile, err = l.sendProfileChange(accountId, change)
+	}
+	if err != nil {
+		return LauncherAccount{}, err
+	}
+
+	var updated LauncherAccount
+	err = l.state.update(func(c *launcherCache) error {
+		stored := findAccount(c, accountId)
+		if stored == nil {
+			return ErrorAccountNotFound
+		}
+
+		stored.Name = profile.Name
+		stored.Skins = profile.Skins
+		stored.Capes = profile.Capes
+		updated = *stored
+		return nil
+	})
+
+	updated.AccessToken = ""
+	updated.RefreshToken = ""
+	return updated, err
+}
+
+func (l *LauncherService) sendProfileChange(accountId string, change func(accessToken string, options minecraft.SkinOptions) (*minecraft.MinecraftProfileResponse, error)) (*minecraft.MinecraftProfileResponse, error) {
+	cache, _ := l.snapshot()
+	account := findAccount(&cache, accountId)
+	if account == nil {
+		return nil, ErrorAccountNotFound
+	}
+	if account.Type != AccountTypeMicrosoft {
+		return nil, ErrorAccountNotMicrosoft
+	}
+	return change(account.AccessToken, l.skinOptions())
+}
+
+func (l *LauncherService) UploadSkin(accountId, path, variant string) (LauncherAccount, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LauncherAccount{}, err
+	}
+	if err := minecraft.ValidateSkin(data); err != nil {
+		return LauncherAccount{}, err
+	}
+
+	return l.changeProfile(accountId, func(accessToken string, options minecraft.SkinOptions) (*minecraft.MinecraftProfileResponse, error) {
+		return minecraft.UploadSkin(accessToken, variant, data, options)
+	})
+}
+
+func (l *LauncherService) SetSkinFromURL(accountId, skinURL, variant string) (LauncherAccount, error) {
+	return l.changeProfile(accountId, func(accessToken string, options minecraft.SkinOptions) (*minecraft.MinecraftProfileResponse, error) {
+		return minecraft.SetSkinFromURL(accessToken, variant, skinURL, options)
+	})
+}
+
+func (l *LauncherService) ResetSkin(accountId string) (LauncherAccount, error) {
+	return l.changeProfile(accountId, minecraft.ResetSkin)
+}
+
+func (l *LauncherService) ShowCape(accountId, capeId string) (LauncherAccount, error) {
+	return l.changeProfile(accountId, func(accessToken string, options minecraft.SkinOptions) (*minecraft.MinecraftProfileResponse, error) {
+		return minecraft.ShowCape(accessToken, capeId, options)
+	})
+}
+
+func (l *LauncherService) HideCape(accountId string) (LauncherAccount, error) {
+	return l.changeProfile(accountId, minecraft.HideCape)
+}
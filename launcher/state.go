@@ -0,0 +1,178 @@
+package launcher
+
+import (
+	"slices"
+	"sync"
+
+	"urodstvo-launcher/minecraft"
+)
+
+// LauncherState is the snapshot sent to the frontend with every "state:changed" event.
+type LauncherState struct {
+	Settings LauncherSettings `json:"settings"`
+	SelectedAccount string `json:"selectedAccount,omitempty"`
+	Accounts []LauncherAccount `json:"accounts"`
+	SelectedInstance string `json:"selectedInstance,omitempty"`
+	Instances []LauncherInstance `json:"instances"`
+	LastPlayedVersion *minecraft.MinecraftVersionInfo `json:"lastPlayedVersion,omitempty"`
+}
+
+// launcherState guards the cache and the options derived from it. Reads hand out copies, so
+// nothing outside the lock ever aliases the live cache.
+type launcherState struct {
+	mu sync.RWMutex
+	// saveMu orders saves. It is held without mu, so a slow credential store never blocks readers or updates.
+	saveMu sync.Mutex
+	cache *launcherCache
+	base minecraft.MinecraftOptions
+	options minecraft.MinecraftOptions
+	changed func(LauncherState)
+}
+
+func newLauncherState(cache *launcherCache, base minecraft.MinecraftOptions) *launcherState {
+	s := &launcherState{
+		cache: cache,
+		base: base,
+	}
+	s.options = s.deriveOptions()
+	return s
+}
+
+func (s *launcherState) deriveOptions() minecraft.MinecraftOptions {
+	options := s.base
+	LoadCacheToMinecraftOptions(*s.cache, &options)
+	return options
+}
+
+func (c *launcherCache) clone() launcherCache {
+	clone := *c
+	if c.Settings != nil {
+		settings := *c.Settings
+		clone.Settings = &settings
+	}
+	if c.LastPlayedVersion != nil {
+		version := *c.LastPlayedVersion
+		clone.LastPlayedVersion = &version
+	}
+	clone.Accounts = slices.Clone(c.Accounts)
+	clone.Instances = slices.Clone(c.Instances)
+	return clone
+}
+
+func cloneOptions(options minecraft.MinecraftOptions) minecraft.MinecraftOptions {
+	options.JvmArguments = slices.Clone(options.JvmArguments)
+	return options
+}
+
+func (c *launcherCache) publicState() LauncherState {
+	state := LauncherState{
+		SelectedAccount: c.SelectedAccount,
+		Accounts: slices.Clone(c.Accounts),
+		SelectedInstance: c.SelectedInstance,
+		Instances: slices.Clone(c.Instances),
+	}
+	if c.LastPlayedVersion != nil {
+		version := *c.LastPlayedVersion
+		state.LastPlayedVersion = &version
+	}
+	if c.Settings != nil {
+		state.Settings = *c.Settings
+	}
+	if state.Accounts == nil {
+		state.Accounts = []LauncherAccount{}
+	}
+	for i := range state.Accounts {
+		state.Accounts[i].AccessToken = ""
+		state.Accounts[i].RefreshToken = ""
+	}
+	if state.Instances == nil {
+		state.Instances = []LauncherInstance{}
+	}
+	return state
+}
+
+func (s *launcherState) snapshot() (launcherCache, minecraft.MinecraftOptions) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.cache.clone(), cloneOptions(s.options)
+}
+
+func (s *launcherState) publicState() LauncherState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.cache.publicState()
+}
+
+func (s *launcherState) setChanged(changed func(LauncherState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.changed = changed
+}
+
+// update runs fn under the write lock, rederives the options, saves the cache and notifies listeners.
+// fn must only touch the cache it is given.
+func (s *launcherState) update(fn func(c *launcherCache) error) error {
+	s.mu.Lock()
+
+	if err := fn(s.cache); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	s.options = s.deriveOptions()
+	state := s.cache.publicState()
+	changed := s.changed
+
+	s.mu.Unlock()
+
+	err := s.save()
+
+	if changed != nil {
+		changed(state)
+	}
+	return err
+}
+
+// save writes the newest cache. Writing credentials can wait on a keyring prompt, so it works on a copy
+// outside the write lock; saveMu makes sure a stale copy never overwrites a newer save.
+func (s *launcherState) save() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.RLock()
+	cache := s.cache.clone()
+	s.mu.RUnlock()
+
+	err := cache.Save()
+
+	// Save records where each account's tokens went; keep those references on the live cache.
+	s.mu.Lock()
+	s.cache.SchemaVersion = cache.SchemaVersion
+	for _, account := range cache.Accounts {
+		if stored := findAccount(s.cache, account.Id); stored != nil {
+			stored.Credentials = account.Credentials
+		}
+	}
+	s.mu.Unlock()
+
+	return err
+}
+
+func (s *launcherState) getRecovery() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.cache.recovery
+}
+
+func (s *launcherState) takeRecovery() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message := s.cache.recovery
+	s.cache.recovery = ""
+	return message
+}
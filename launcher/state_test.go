@@ -0,0 +1,219 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"urodstvo-launcher/minecraft"
+)
+
+// memoryCredentialStore keeps secrets in memory, so tests never touch the keyring or the machine secret.
+type memoryCredentialStore struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *memoryCredentialStore) Name() string {
+	return "memory"
+}
+
+func (s *memoryCredentialStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[key]
+	if !ok {
+		return "", ErrorCredentialNotFound
+	}
+	return value, nil
+}
+
+func (s *memoryCredentialStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+	return nil
+}
+
+func (s *memoryCredentialStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.values, key)
+	return nil
+}
+
+const _testAccounts = 4
+
+func newTestLauncherService(t *testing.T) (*LauncherService, *memoryCredentialStore) {
+	t.Helper()
+
+	SetDataDirectory(t.TempDir())
+	t.Cleanup(func() { SetDataDirectory("") })
+
+	store := &memoryCredentialStore{values: map[string]string{}}
+	cache := &launcherCache{
+		Settings: &LauncherSettings{GameDirectory: t.TempDir(), AllocatedRAM: 2048},
+		credentials: &credentialVault{
+			stores: []CredentialStore{store},
+			written: map[string]string{},
+		},
+	}
+
+	// Expired tokens make every launch refresh first.
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	for i := range _testAccounts {
+		cache.Accounts = append(cache.Accounts, LauncherAccount{
+			Id: fmt.Sprintf("account-%d", i),
+			Type: AccountTypeMicrosoft,
+			Name: fmt.Sprintf("Player%d", i),
+			AccessToken: fmt.Sprintf("access-%d", i),
+			RefreshToken: fmt.Sprintf("refresh-%d", i),
+			ExpiresAt: expired,
+		})
+	}
+
+	l := &LauncherService{
+		state: newLauncherState(cache, minecraft.MinecraftOptions{LauncherName: "test"}),
+	}
+	// The refresh is rejected, so StartMinecraft stops before it would download or start anything.
+	l.SetTokenRefresher(func(string) (*minecraft.CompleteLoginResponse, error) {
+		return nil, minecraft.ErrInvalidRefreshToken
+	})
+	return l, store
+}
+
+func TestLauncherStateConcurrentUpdates(t *testing.T) {
+	l, store := newTestLauncherService(t)
+
+	var changes atomic.Int32
+	l.state.setChanged(func(state LauncherState) {
+		changes.Add(1)
+		for _, account := range state.Accounts {
+			if account.AccessToken != "" || account.RefreshToken != "" {
+				t.Errorf("state:changed carried tokens for %s", account.Id)
+			}
+		}
+	})
+
+	const workers = 8
+	const rounds = 40
+
+	var instances atomic.Int32
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range rounds {
+				switch (w + i) % 5 {
+				case 0:
+					// account-4 does not exist and clears the selection.
+					l.SelectAccount(fmt.Sprintf("account-%d", i%(_testAccounts+1)))
+				case 1:
+					settings := l.GetLauncherSettings()
+					settings.AllocatedRAM = 1024 + w*rounds + i
+					if err := l.SaveLauncherSettings(settings); err != nil {
+						t.Errorf("SaveLauncherSettings: %v", err)
+					}
+				case 2:
+					result := l.StartMinecraft(minecraft.MinecraftVersionInfo{Id: "1.21.1"})
+					if result.Started || result.Error == "" {
+						t.Errorf("StartMinecraft = %+v, want it to stop at the rejected refresh or the missing account", result)
+					}
+				case 3:
+					err := l.state.update(func(c *launcherCache) error {
+						c.Instances = append(c.Instances, LauncherInstance{Id: fmt.Sprintf("instance-%d-%d", w, i)})
+						return nil
+					})
+					if err != nil {
+						t.Errorf("update: %v", err)
+					}
+					instances.Add(1)
+				case 4:
+					state := l.GetState()
+					cache, options := l.snapshot()
+					if cache.Settings == nil || state.Settings.GameDirectory != options.GameDirectory {
+						t.Errorf("snapshot settings %+v do not match options game directory %q", cache.Settings, options.GameDirectory)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	cache, options := l.snapshot()
+	if len(cache.Instances) != int(instances.Load()) {
+		t.Errorf("cache has %d instances, want %d", len(cache.Instances), instances.Load())
+	}
+	if cache.SelectedAccount != "" && findAccount(&cache, cache.SelectedAccount) == nil {
+		t.Errorf("selected account %q does not exist", cache.SelectedAccount)
+	}
+
+	want := l.state.base
+	LoadCacheToMinecraftOptions(cache, &want)
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("options were not derived from the final cache:\n got %+v\nwant %+v", options, want)
+	}
+	if changes.Load() == 0 {
+		t.Error("no state:changed notifications were sent")
+	}
+
+	// The last save ran after the last update, so the file holds the final state without tokens.
+	data, err := os.ReadFile(getLauncherCachePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "access-") || strings.Contains(string(data), "refresh-") {
+		t.Error("launcher cache contains plaintext tokens")
+	}
+
+	var saved launcherCache
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.SelectedAccount != cache.SelectedAccount || len(saved.Instances) != len(cache.Instances) {
+		t.Errorf("saved cache selects %q with %d instances, want %q with %d",
+			saved.SelectedAccount, len(saved.Instances), cache.SelectedAccount, len(cache.Instances))
+	}
+	if !reflect.DeepEqual(saved.Settings, cache.Settings) {
+		t.Errorf("saved settings = %+v, want %+v", saved.Settings, cache.Settings)
+	}
+
+	for _, account := range cache.Accounts {
+		ref := "memory:" + credentialKey(account)
+		if account.Credentials != ref {
+			t.Errorf("account %s credentials = %q, want %q", account.Id, account.Credentials, ref)
+		}
+		if _, err := store.Get(credentialKey(account)); err != nil {
+			t.Errorf("tokens for %s were not stored: %v", account.Id, err)
+		}
+	}
+}
+
+func TestLauncherStateSnapshotIsolation(t *testing.T) {
+	l, _ := newTestLauncherService(t)
+
+	cache, options := l.snapshot()
+	arguments := len(options.JvmArguments)
+	cache.Settings.AllocatedRAM = 1
+	cache.Accounts[0].Name = "changed"
+	options.JvmArguments = append(options.JvmArguments, "-Dchanged")
+
+	again, againOptions := l.snapshot()
+	if again.Settings.AllocatedRAM == 1 || again.Accounts[0].Name == "changed" {
+		t.Error("changing a snapshot changed the live cache")
+	}
+	if len(againOptions.JvmArguments) != arguments {
+		t.Error("changing snapshot options changed the live options")
+	}
+}
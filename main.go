@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"flag"
 	"fmt"
 	"log"
 
@@ -15,8 +16,15 @@ import (
 var assets embed.FS
 
 func main() {	
-	authService := auth.NewAuthService()
+	dataDir := flag.String("data-dir", "", "directory for launcher state (overrides URODSTVO_LAUNCHER_DATA_DIR)")
+	flag.Parse()
+	if *dataDir != "" {
+		launcher.SetDataDirectory(*dataDir)
+	}
+
+	authService := auth.NewAuthService(launcher.GetDataDirectory())
 	launcherService := launcher.NewLauncherService()
+	launcherService.SetTokenRefresher(authService.RefreshLogin)
 
 	app := application.New(application.Options{
 		Name: "Minecraft Launcher",
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 
@@ -131,13 +132,17 @@ func getAuthorizationToken(clientID, redirectURI, authCode string, clientSecret,
 	if err != nil {
 		return nil, err
 	}
+	if result.Error != "" {
+		return nil, ErrMicrosoftAuthFailed.with(resp.StatusCode, result.Error+": "+result.ErrorDescription)
+	}
 	return &result, nil
 }
 
-func refreshAuthorizationToken(clientID, clientSecret string, redirectURI *string, refreshToken string) (*AuthorizationTokenResponse, error) {
+// refreshAuthorizationToken uses the same endpoint, client and scope as sign-in, since the refresh token was issued there.
+func refreshAuthorizationToken(clientID, clientSecret, refreshToken string) (*AuthorizationTokenResponse, error) {
 	form := url.Values{}
 	form.Set("client_id", clientID)
-	form.Set("scope", "XboxLive.signin offline_access")
+	form.Set("scope", _scope)
 	form.Set("refresh_token", refreshToken)
 	form.Set("grant_type", "refresh_token")
 
@@ -145,9 +150,7 @@ func refreshAuthorizationToken(clientID, clientSecret string, redirectURI *strin
 		form.Set("client_secret", clientSecret)
 	}
 
-	_ = redirectURI
-
-	req, err := http.NewRequest("POST", "https://login.live.com/oauth20_token.srf", strings.NewReader(form.Encode()))
+	req, err := http.NewRequest("POST", _tokenURL, strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -162,11 +165,22 @@ func refreshAuthorizationToken(clientID, clientSecret string, redirectURI *strin
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("failed to refresh token: %s", resp.Status)
+	}
+
 	var result *AuthorizationTokenResponse
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
 		return nil, err
 	}
+	if result.Error != "" {
+		// Only a rejected grant means the user has to sign in again; anything else may go away on retry.
+		if result.Error == "invalid_grant" || resp.StatusCode == http.StatusUnauthorized {
+			return nil, ErrInvalidRefreshToken.with(resp.StatusCode, result.Error+": "+result.ErrorDescription)
+		}
+		return nil, ErrMicrosoftAuthFailed.with(resp.StatusCode, result.Error+": "+result.ErrorDescription)
+	}
 	return result, nil
 }
 
@@ -202,11 +216,18 @@ func authenticateWithXBL(accessToken string) (*XBLResponse, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrXboxAuthFailed.with(resp.StatusCode, resp.Status)
+	}
+
 	var result *XBLResponse
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
 		return nil, err
 	}
+	if len(result.DisplayClaims.Xui) == 0 {
+		return nil, ErrXboxAuthFailed.with(resp.StatusCode, "response has no user hash")
+	}
 	return result, nil
 }
 
@@ -243,6 +264,12 @@ func authenticateWithXSTS(xblToken string) (*XSTSResponse, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		var xstsErr xstsErrorResponse
+		json.NewDecoder(resp.Body).Decode(&xstsErr)
+		return nil, getXSTSError(xstsErr.XErr, resp.StatusCode, xstsErr.Message)
+	}
+
 	var result XSTSResponse
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
@@ -277,6 +304,12 @@ func authenticateWithMinecraft(userhash, xstsToken string) (*MinecraftAuthentica
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		var servicesErr minecraftServicesErrorResponse
+		json.NewDecoder(resp.Body).Decode(&servicesErr)
+		return nil, getMinecraftServicesError(resp.StatusCode, servicesErr)
+	}
+
 	var result *MinecraftAuthenticateResponse
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
@@ -285,6 +318,36 @@ func authenticateWithMinecraft(userhash, xstsToken string) (*MinecraftAuthentica
 	return result, nil
 }
 
+type xstsErrorResponse struct {
+	Identity string `json:"Identity"`
+	XErr     int64  `json:"XErr"`
+	Message  string `json:"Message"`
+	Redirect string `json:"Redirect"`
+}
+
+type minecraftServicesErrorResponse struct {
+	Path         string `json:"path"`
+	Error        string `json:"error"`
+	ErrorType    string `json:"errorType"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+func getMinecraftServicesError(status int, resp minecraftServicesErrorResponse) *AuthError {
+	details := resp.ErrorMessage
+	if details == "" {
+		details = resp.Error
+	}
+
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrTooManyRequests.with(status, details)
+	case status == http.StatusForbidden && strings.Contains(strings.ToLower(resp.ErrorMessage), "invalid app registration"):
+		return ErrAzureAppNotPermitted.with(status, details)
+	default:
+		return ErrMinecraftAuthFailed.with(status, details)
+	}
+}
+
 func GetStoreInformation(accessToken string) (*MinecraftStoreResponse, error) {
 	req, err := http.NewRequest("GET", "https://api.minecraftservices.com/entitlements/mcstore", nil)
 	if err != nil {
@@ -301,6 +364,10 @@ func GetStoreInformation(accessToken string) (*MinecraftStoreResponse, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch entitlements: %s", resp.Status)
+	}
+
 	var result *MinecraftStoreResponse
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
@@ -309,8 +376,8 @@ func GetStoreInformation(accessToken string) (*MinecraftStoreResponse, error) {
 	return result, nil
 }
 
-func getProfile(accessToken string) (*MinecraftProfileResponse, error) {
-	req, err := http.NewRequest("GET", "https://api.minecraftservices.com/minecraft/profile", nil)
+func getProfile(apiURL, accessToken string) (*MinecraftProfileResponse, error) {
+	req, err := http.NewRequest("GET", apiURL+"/minecraft/profile", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -333,91 +400,83 @@ func getProfile(accessToken string) (*MinecraftProfileResponse, error) {
 	return result, nil
 }
 
-func CompleteLogin(clientID, clientSecret, redirectURI, authCode, codeVerifier string) (*CompleteLoginResponse, error) {
-	tokenResp, err := getAuthorizationToken(clientID, redirectURI, authCode, clientSecret, codeVerifier)
-	if err != nil {
-		return nil, err
-	}
+// completeMinecraftLogin turns a Microsoft access token into a Minecraft session via XBL, XSTS and Minecraft Services.
+func completeMinecraftLogin(tokenResp *AuthorizationTokenResponse) (*CompleteLoginResponse, error) {
 	accessToken := tokenResp.AccessToken
-	
+
 	xblResp, err := authenticateWithXBL(accessToken)
 	if err != nil {
 		return nil, err
 	}
 	xblToken := xblResp.Token
 	userhash := xblResp.DisplayClaims.Xui[0].Uhs
-	
+
 	xstsResp, err := authenticateWithXSTS(xblToken)
 	if err != nil {
 		return nil, err
 	}
 	xstsToken := xstsResp.Token
-	
+
+	obtainedAt := time.Now().UTC().Format(time.RFC3339)
 	mcResp, err := authenticateWithMinecraft(userhash, xstsToken)
 	if err != nil {
 		return nil, err
 	}
 	mcAccessToken := mcResp.AccessToken
 	if mcAccessToken == "" {
-		return nil, errors.New("AzureAppNotPermitted")
+		return nil, ErrAzureAppNotPermitted
 	}
-	
-	profile, err := getProfile(mcAccessToken)
+
+	profile, err := getProfile(DefaultMinecraftServicesURL, mcAccessToken)
 	if err != nil {
 		return nil, err
 	}
-	if profile.Error != "" && profile.Error == "NOT_FOUND" {
-		return nil, errors.New("AccountNotOwnMinecraft")
+	ownership, ownershipErr := GetMinecraftOwnership(mcAccessToken)
+	if profile.Error == "NOT_FOUND" {
+		// Without a profile the account either never bought the game or has not picked a name yet.
+		if ownershipErr == nil && !ownership.CanPlay() {
+			return nil, ErrGameNotOwned
+		}
+		return nil, ErrProfileNotFound
+	}
+	if profile.Error != "" {
+		return nil, ErrMinecraftAuthFailed.with(0, profile.Error+": "+profile.ErrorMessage)
 	}
+
+	// A store outage should not lock out paying players, so only a definite answer turns on demo mode.
+	demo := false
+	if errors.Is(ownershipErr, ErrorEntitlementsSignatureInvalid) {
+		demo = true
+	} else if ownershipErr == nil {
+		demo = !ownership.CanPlay()
+	}
+
 	var response *CompleteLoginResponse = &CompleteLoginResponse{
 		MinecraftProfileResponse: *profile,
 		AccessToken: mcResp.AccessToken,
 		RefreshToken: tokenResp.RefreshToken,
+		ExpiresIn: mcResp.ExpiresIn,
+		ObtainedAt: obtainedAt,
+		Ownership: ownership,
+		Demo: demo,
 	}
 	return response, nil
 }
 
-func CompleteRefresh(clientID, clientSecret, redirectURI, refreshToken string) (*CompleteLoginResponse, error) {
-	tokenResp, err := refreshAuthorizationToken(clientID, clientSecret, &redirectURI, refreshToken)
-	if err != nil {
-		return nil, errors.New("InvalidRefreshToken")
-	}
-	
-	accessToken := tokenResp.AccessToken
-
-	xblResp, err := authenticateWithXBL(accessToken)
-	if err != nil {
-		return nil, err
-	}
-	xblToken := xblResp.Token
-	userhash := xblResp.DisplayClaims.Xui[0].Uhs
-
-	xstsResp, err := authenticateWithXSTS(xblToken)
-	if err != nil {
-		return nil, err
-	}
-	xstsToken := xstsResp.Token
-
-	mcResp, err := authenticateWithMinecraft(userhash, xstsToken)
+func CompleteLogin(clientID, clientSecret, redirectURI, authCode, codeVerifier string) (*CompleteLoginResponse, error) {
+	tokenResp, err := getAuthorizationToken(clientID, redirectURI, authCode, clientSecret, codeVerifier)
 	if err != nil {
 		return nil, err
 	}
-	mcAccessToken := mcResp.AccessToken
+	return completeMinecraftLogin(tokenResp)
+}
 
-	profile, err := getProfile(mcAccessToken)
+func CompleteRefresh(clientID, clientSecret, refreshToken string) (*CompleteLoginResponse, error) {
+	tokenResp, err := refreshAuthorizationToken(clientID, clientSecret, refreshToken)
 	if err != nil {
 		return nil, err
 	}
-	if profile.Error != "" && profile.Error == "NOT_FOUND" {
-		return nil, errors.New("AccountNotOwnMinecraft")
-	}
-
-	var response *CompleteLoginResponse = &CompleteLoginResponse{
-		MinecraftProfileResponse: *profile,
-		AccessToken: mcResp.AccessToken,
-		RefreshToken: tokenResp.RefreshToken,
-	}
-	return response, nil
+	return completeMinecraftLogin(tokenResp)
 }
 
 
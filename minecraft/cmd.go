@@ -70,7 +70,10 @@ func replaceArguments(argstr string, versionData ClientJson, path string, option
 		options.Token = "{token}"
 	}
 	argstr = strings.ReplaceAll(argstr, "${auth_access_token}", options.Token)
-	argstr = strings.ReplaceAll(argstr, "${user_type}", "msa")
+	if options.UserType == "" {
+		options.UserType = UserTypeMSA
+	}
+	argstr = strings.ReplaceAll(argstr, "${user_type}", options.UserType)
 	argstr = strings.ReplaceAll(argstr, "${version_type}", versionData.Type)
 	argstr = strings.ReplaceAll(argstr, "${user_properties}", "{}")
 	if options.ResolutionWidth == "" {
@@ -112,8 +115,8 @@ func replaceArguments(argstr string, versionData ClientJson, path string, option
 func getArgumentsString(versionData ClientJson, path string, options MinecraftOptions, classpath string) []string {
 	arglist := []string{}
 
-	args := strings.SplitSeq(versionData.MinecraftArguments, " ")
-	for v := range args {
+	args := strings.Split(versionData.MinecraftArguments, " ")
+	for _, v := range args {
 		v = replaceArguments(v, versionData, path, options, classpath)
 		arglist = append(arglist, v)
 	}
@@ -171,10 +174,10 @@ func getArguments(data []any, versionData ClientJson, path string, options Minec
 }
 
 func GetMinecraftCommand(version string, options MinecraftOptions) ([]string, error) {
+	path := getInstallDirectory(options)
 	if options.GameDirectory == "" {
-		options.GameDirectory = GetMinecraftDirectory()
+		options.GameDirectory = path
 	}
-	path := options.GameDirectory
 
 	versionDir := filepath.Join(path, "versions", version)
 	if _, err := os.Stat(versionDir); os.IsNotExist(err) {
@@ -218,6 +221,8 @@ if data.InheritsFrom != "" {
 		command = append(command, options.JvmArguments...)
 	}
 
+	command = append(command, getAuthlibInjectorArguments(options)...)
+
 	if data.Arguments != nil {
 		if data.Arguments.Jvm != nil {
 			command = append(command, getArguments(data.Arguments.Jvm, data, path, options, classpath)...)
@@ -0,0 +1,357 @@
+package minecraft
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	ContentMods          string = "mods"
+	ContentResourcePacks string = "resourcepacks"
+	ContentShaderPacks   string = "shaderpacks"
+	ContentDataPacks     string = "datapacks"
+)
+
+const (
+	disabledDirectory = ".disabled"
+	safeModeFile      = ".safe-mode.json"
+)
+
+var ErrorUnknownContentType error = errors.New("unknown content type")
+
+func getContentDirectory(gameDir, contentType, world string) (string, error) {
+	switch contentType {
+	case ContentMods, ContentResourcePacks, ContentShaderPacks:
+		return filepath.Join(gameDir, contentType), nil
+	case ContentDataPacks:
+		if world == "" || filepath.Base(world) != world {
+			return "", fmt.Errorf("invalid world name: %q", world)
+		}
+		return filepath.Join(gameDir, "saves", world, "datapacks"), nil
+	default:
+		return "", ErrorUnknownContentType
+	}
+}
+
+func isValidContentName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name
+}
+
+func ListContent(gameDir, contentType, world string) ([]ContentEntry, error) {
+	dir, err := getContentDirectory(gameDir, contentType, world)
+	if err != nil {
+		return nil, err
+	}
+
+	var enabledPacks []string
+	if contentType == ContentResourcePacks {
+		enabledPacks, err = getEnabledResourcePacks(gameDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entries := []ContentEntry{}
+	addEntries := func(dir string, disabled bool) error {
+		dirEntries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		for _, dirEntry := range dirEntries {
+			fileName := dirEntry.Name()
+			if strings.HasPrefix(fileName, ".") {
+				continue
+			}
+
+			entry := ContentEntry{
+				Type:     contentType,
+				Name:     fileName,
+				FileName: fileName,
+				Path:     filepath.Join(dir, fileName),
+				Enabled:  !disabled,
+				World:    world,
+			}
+
+			switch contentType {
+			case ContentMods:
+				if dirEntry.IsDir() {
+					continue
+				}
+				lower := strings.ToLower(fileName)
+				if !strings.HasSuffix(lower, ".jar") && !strings.HasSuffix(lower, ".jar"+disabledSuffix) {
+					continue
+				}
+				entry.Name = strings.TrimSuffix(fileName, disabledSuffix)
+				entry.Enabled = entry.Name == fileName
+			case ContentResourcePacks:
+				entry.Enabled = slices.Contains(enabledPacks, "file/"+fileName)
+			}
+
+			entries = append(entries, entry)
+		}
+		return nil
+	}
+
+	if err := addEntries(dir, false); err != nil {
+		return nil, err
+	}
+	if contentType == ContentShaderPacks || contentType == ContentDataPacks {
+		if err := addEntries(filepath.Join(dir, disabledDirectory), true); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+
+	return entries, nil
+}
+
+func SetContentEnabled(gameDir, contentType, world string, names []string, enabled bool) error {
+	dir, err := getContentDirectory(gameDir, contentType, world)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if !isValidContentName(name) {
+			return fmt.Errorf("invalid content name: %q", name)
+		}
+	}
+
+	switch contentType {
+	case ContentMods:
+		return setModsEnabled(dir, names, enabled)
+	case ContentResourcePacks:
+		return setResourcePacksEnabled(gameDir, names, enabled)
+	default:
+		return setMovedContentEnabled(dir, names, enabled)
+	}
+}
+
+func setModsEnabled(modsDir string, names []string, enabled bool) error {
+	for _, name := range names {
+		name = strings.TrimSuffix(name, disabledSuffix)
+		enabledPath := filepath.Join(modsDir, name)
+		disabledPath := enabledPath + disabledSuffix
+
+		from, to := disabledPath, enabledPath
+		if !enabled {
+			from, to = enabledPath, disabledPath
+		}
+
+		if !fileExists(from) {
+			if fileExists(to) {
+				continue
+			}
+			return fmt.Errorf("mod not found: %s", name)
+		}
+		if err := os.Rename(from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setMovedContentEnabled(dir string, names []string, enabled bool) error {
+	disabledDir := filepath.Join(dir, disabledDirectory)
+	if err := os.MkdirAll(disabledDir, 0755); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		from, to := filepath.Join(disabledDir, name), filepath.Join(dir, name)
+		if !enabled {
+			from, to = to, from
+		}
+
+		if _, err := os.Stat(from); os.IsNotExist(err) {
+			if _, err := os.Stat(to); err == nil {
+				continue
+			}
+			return fmt.Errorf("content not found: %s", name)
+		}
+		if err := os.Rename(from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readOptionsTxt(gameDir string) ([]string, error) {
+	file, err := os.Open(filepath.Join(gameDir, "options.txt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+func getOptionsTxtValue(lines []string, key string) (string, int) {
+	for i, line := range lines {
+		if value, found := strings.CutPrefix(line, key+":"); found {
+			return value, i
+		}
+	}
+	return "", -1
+}
+
+func getEnabledResourcePacks(gameDir string) ([]string, error) {
+	lines, err := readOptionsTxt(gameDir)
+	if err != nil {
+		return nil, err
+	}
+
+	value, index := getOptionsTxtValue(lines, "resourcePacks")
+	if index < 0 || value == "" {
+		return []string{"vanilla"}, nil
+	}
+
+	var packs []string
+	if err := json.Unmarshal([]byte(value), &packs); err != nil {
+		return nil, fmt.Errorf("invalid resourcePacks in options.txt: %w", err)
+	}
+	return packs, nil
+}
+
+func setResourcePacksEnabled(gameDir string, names []string, enabled bool) error {
+	lines, err := readOptionsTxt(gameDir)
+	if err != nil {
+		return err
+	}
+
+	packs, err := getEnabledResourcePacks(gameDir)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		pack := "file/" + name
+		if enabled {
+			if _, err := os.Stat(filepath.Join(gameDir, ContentResourcePacks, name)); err != nil {
+				return fmt.Errorf("resource pack not found: %s", name)
+			}
+			if !slices.Contains(packs, pack) {
+				packs = append(packs, pack)
+			}
+		} else {
+			packs = slices.DeleteFunc(packs, func(p string) bool { return p == pack })
+		}
+	}
+
+	data, err := json.Marshal(packs)
+	if err != nil {
+		return err
+	}
+
+	line := "resourcePacks:" + string(data)
+	if _, index := getOptionsTxtValue(lines, "resourcePacks"); index >= 0 {
+		lines[index] = line
+	} else {
+		lines = append(lines, line)
+	}
+
+	if !enabled {
+		if value, index := getOptionsTxtValue(lines, "incompatibleResourcePacks"); index >= 0 {
+			var incompatible []string
+			if json.Unmarshal([]byte(value), &incompatible) == nil {
+				incompatible = slices.DeleteFunc(incompatible, func(p string) bool {
+					return slices.ContainsFunc(names, func(name string) bool { return p == "file/"+name })
+				})
+				data, _ := json.Marshal(incompatible)
+				lines[index] = "incompatibleResourcePacks:" + string(data)
+			}
+		}
+	}
+
+	return os.WriteFile(filepath.Join(gameDir, "options.txt"), []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func EnableSafeMode(gameDir string) ([]string, error) {
+	modsDir := filepath.Join(gameDir, "mods")
+	markerPath := filepath.Join(modsDir, safeModeFile)
+	if fileExists(markerPath) {
+		if err := RestoreSafeMode(gameDir); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := ListContent(gameDir, ContentMods, "")
+	if err != nil {
+		return nil, err
+	}
+
+	disabled := []string{}
+	for _, entry := range entries {
+		if entry.Enabled {
+			disabled = append(disabled, entry.Name)
+		}
+	}
+	if len(disabled) == 0 {
+		return disabled, nil
+	}
+
+	data, err := json.Marshal(disabled)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(markerPath, data, 0644); err != nil {
+		return nil, err
+	}
+
+	if err := setModsEnabled(modsDir, disabled, false); err != nil {
+		RestoreSafeMode(gameDir)
+		return nil, err
+	}
+	return disabled, nil
+}
+
+func RestoreSafeMode(gameDir string) error {
+	modsDir := filepath.Join(gameDir, "mods")
+	markerPath := filepath.Join(modsDir, safeModeFile)
+
+	disabled, err := readJSON[[]string](markerPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var restoreErr error
+	for _, name := range disabled {
+		if !isValidContentName(name) {
+			continue
+		}
+		if err := setModsEnabled(modsDir, []string{name}, true); err != nil && restoreErr == nil {
+			restoreErr = err
+		}
+	}
+	if restoreErr != nil {
+		return restoreErr
+	}
+
+	return os.Remove(markerPath)
+}
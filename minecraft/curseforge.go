@@ -0,0 +1,272 @@
+package minecraft
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const _curseForgeAPIURL = "https://api.curseforge.com"
+
+const (
+	curseForgeClassResourcePacks = 12
+	curseForgeClassWorlds        = 17
+	curseForgeClassShaderPacks   = 6552
+)
+
+var (
+	ErrorInvalidCurseForgeModpack    error = errors.New("invalid curseforge modpack")
+	ErrorCurseForgeAPIKeyMissing     error = errors.New("curseforge api key is not configured")
+	ErrorCurseForgeDirectoryRequired error = errors.New("a curseforge modpack directory is required")
+)
+
+func curseForgeRequest[T any](options CurseForgeInstallOptions, path string, body any) (T, error) {
+	var result struct {
+		Data T `json:"data"`
+	}
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return result.Data, err
+	}
+
+	apiURL := options.APIURL
+	if apiURL == "" {
+		apiURL = _curseForgeAPIURL
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimSuffix(apiURL, "/")+path, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return result.Data, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", getUserAgent())
+	req.Header.Set("x-api-key", options.APIKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return result.Data, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return result.Data, fmt.Errorf("curseforge api returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result.Data, err
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result.Data, err
+	}
+	return result.Data, nil
+}
+
+func readCurseForgeManifest(r *zip.Reader) (CurseForgeManifest, error) {
+	var manifest CurseForgeManifest
+
+	f, err := r.Open("manifest.json")
+	if err != nil {
+		return manifest, fmt.Errorf("%w: manifest.json not found", ErrorInvalidCurseForgeModpack)
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("%w: %v", ErrorInvalidCurseForgeModpack, err)
+	}
+	if manifest.ManifestType != "minecraftModpack" || manifest.Minecraft.Version == "" {
+		return manifest, fmt.Errorf("%w: not a minecraft modpack", ErrorInvalidCurseForgeModpack)
+	}
+
+	return manifest, nil
+}
+
+func getCurseForgeLoader(modLoaders []CurseForgeManifestModLoader) (string, string) {
+	var selected *CurseForgeManifestModLoader
+	for i, modLoader := range modLoaders {
+		if selected == nil || modLoader.Primary {
+			selected = &modLoaders[i]
+		}
+	}
+	if selected == nil {
+		return LoaderVanilla, ""
+	}
+
+	for _, loader := range []string{LoaderNeoForge, LoaderForge, LoaderFabric, LoaderQuilt} {
+		if version, found := strings.CutPrefix(selected.Id, loader+"-"); found {
+			return loader, version
+		}
+	}
+	return selected.Id, ""
+}
+
+func getCurseForgeFileDirectory(classId int) string {
+	switch classId {
+	case curseForgeClassResourcePacks:
+		return "resourcepacks"
+	case curseForgeClassShaderPacks:
+		return "shaderpacks"
+	default:
+		return "mods"
+	}
+}
+
+func GetCurseForgeModpackInformation(path string) (CurseForgeManifest, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return CurseForgeManifest{}, err
+	}
+	defer r.Close()
+
+	return readCurseForgeManifest(&r.Reader)
+}
+
+func InstallCurseForgeModpack(path, minecraftDir, modpackDir string, options CurseForgeInstallOptions, callback *Callback) (*CurseForgeInstallReport, error) {
+	callback = getCallback(callback)
+
+	if options.APIKey == "" {
+		return nil, ErrorCurseForgeAPIKeyMissing
+	}
+	// Overrides would overwrite the shared game directory's mods and configs, so there is no default.
+	if modpackDir == "" {
+		return nil, ErrorCurseForgeDirectoryRequired
+	}
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	manifest, err := readCurseForgeManifest(&r.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(modpackDir, 0755); err != nil {
+		return nil, err
+	}
+
+	var modIds, fileIds []int
+	for _, file := range manifest.Files {
+		if file.Required {
+			modIds = append(modIds, file.ProjectID)
+			fileIds = append(fileIds, file.FileID)
+		}
+	}
+
+	callback.Status("Resolving CurseForge Files...")
+	mods, err := curseForgeRequest[[]curseForgeMod](options, "/v1/mods", map[string][]int{"modIds": modIds})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve curseforge projects: %w", err)
+	}
+	files, err := curseForgeRequest[[]curseForgeFile](options, "/v1/mods/files", map[string][]int{"fileIds": fileIds})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve curseforge files: %w", err)
+	}
+
+	modMap := make(map[int]curseForgeMod, len(mods))
+	for _, mod := range mods {
+		modMap[mod.Id] = mod
+	}
+	fileMap := make(map[int]curseForgeFile, len(files))
+	for _, file := range files {
+		fileMap[file.Id] = file
+	}
+
+	report := &CurseForgeInstallReport{
+		Name:      manifest.Name,
+		Installed: []string{},
+		Skipped:   []CurseForgeSkippedFile{},
+	}
+
+	var downloads []MrpackFile
+	for _, entry := range manifest.Files {
+		if !entry.Required {
+			continue
+		}
+
+		mod := modMap[entry.ProjectID]
+		file, found := fileMap[entry.FileID]
+		relPath := filepath.ToSlash(filepath.Join(getCurseForgeFileDirectory(mod.ClassId), file.FileName))
+
+		skipped := CurseForgeSkippedFile{
+			ProjectID:   entry.ProjectID,
+			FileID:      entry.FileID,
+			ProjectName: mod.Name,
+			FileName:    file.FileName,
+			Path:        relPath,
+			WebsiteURL:  mod.Links.WebsiteUrl,
+		}
+
+		switch {
+		case !found:
+			skipped.Reason = "file not found"
+			report.Skipped = append(report.Skipped, skipped)
+			continue
+		case mod.ClassId == curseForgeClassWorlds:
+			skipped.Reason = "worlds are not installed automatically"
+			report.Skipped = append(report.Skipped, skipped)
+			continue
+		case file.DownloadUrl == nil || *file.DownloadUrl == "":
+			skipped.Reason = "distribution disabled by the author"
+			if skipped.WebsiteURL != "" {
+				skipped.WebsiteURL = fmt.Sprintf("%s/download/%d", strings.TrimSuffix(skipped.WebsiteURL, "/"), entry.FileID)
+			}
+			report.Skipped = append(report.Skipped, skipped)
+			continue
+		}
+
+		hashes := map[string]string{}
+		for _, h := range file.Hashes {
+			if h.Algo == 1 {
+				hashes["sha1"] = h.Value
+			}
+		}
+
+		downloads = append(downloads, MrpackFile{
+			Path:      relPath,
+			Hashes:    hashes,
+			Downloads: []string{*file.DownloadUrl},
+		})
+		report.Installed = append(report.Installed, relPath)
+	}
+
+	if err := installMrpackFiles(downloads, modpackDir, *callback); err != nil {
+		return nil, err
+	}
+
+	callback.Status("Extracting Overrides...")
+	overrides := manifest.Overrides
+	if overrides == "" {
+		overrides = "overrides"
+	}
+	if _, err := extractZipDirectory(&r.Reader, strings.TrimSuffix(overrides, "/")+"/", modpackDir); err != nil {
+		return nil, fmt.Errorf("error extracting overrides: %w", err)
+	}
+
+	loader, loaderVersion := getCurseForgeLoader(manifest.Minecraft.ModLoaders)
+	report.LaunchVersion = getLoaderVersionId(loader, manifest.Minecraft.Version, loaderVersion)
+
+	if !options.SkipDependenciesInstall {
+		installedVersion, err := InstallLoader(loader, manifest.Minecraft.Version, loaderVersion, MinecraftOptions{InstallDirectory: minecraftDir}, callback)
+		if err != nil {
+			return nil, fmt.Errorf("failed to install %s %s: %w", loader, manifest.Minecraft.Version, err)
+		}
+		report.LaunchVersion = installedVersion
+	}
+
+	callback.Status(fmt.Sprintf("Installed %d files, skipped %d.", len(report.Installed), len(report.Skipped)))
+	return report, nil
+}
@@ -0,0 +1,143 @@
+package minecraft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	_deviceCodeURL   = "https://login.microsoftonline.com/consumers/oauth2/v2.0/devicecode"
+	_deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+
+	_deviceCodeMaxInterval = time.Minute
+)
+
+var (
+	ErrorDeviceCodeDeclined = errors.New("the sign-in request was declined")
+	ErrorDeviceCodeExpired  = errors.New("the sign-in code expired before it was used")
+)
+
+// RequestDeviceCode starts the device authorization grant. The user enters UserCode at VerificationURI on
+// any device while PollDeviceCodeToken waits for them.
+func RequestDeviceCode(clientID string) (*DeviceCodeResponse, error) {
+	form := url.Values{}
+	form.Set("client_id", clientID)
+	form.Set("scope", _scope)
+
+	req, err := http.NewRequest("POST", _deviceCodeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", getUserAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		DeviceCodeResponse
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("device code request failed: %s: %s", result.Error, result.ErrorDescription)
+	}
+	if result.DeviceCode == "" {
+		return nil, fmt.Errorf("device code request failed: %s", resp.Status)
+	}
+	return &result.DeviceCodeResponse, nil
+}
+
+func getDeviceCodeToken(clientID, deviceCode string) (*AuthorizationTokenResponse, error) {
+	form := url.Values{}
+	form.Set("client_id", clientID)
+	form.Set("grant_type", _deviceCodeGrant)
+	form.Set("device_code", deviceCode)
+
+	req, err := http.NewRequest("POST", _tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", getUserAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result AuthorizationTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PollDeviceCodeToken polls the token endpoint until the user finishes signing in, the code expires or ctx is
+// cancelled. It waits the server's interval between attempts, slows down when asked to and backs off on
+// network errors.
+func PollDeviceCodeToken(ctx context.Context, clientID string, code *DeviceCodeResponse) (*AuthorizationTokenResponse, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	var deadline <-chan time.Time
+	if code.ExpiresIn > 0 {
+		timer := time.NewTimer(time.Duration(code.ExpiresIn) * time.Second)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, ErrorDeviceCodeExpired
+		case <-time.After(interval):
+		}
+
+		result, err := getDeviceCodeToken(clientID, code.DeviceCode)
+		if err != nil {
+			interval = min(interval*2, _deviceCodeMaxInterval)
+			continue
+		}
+
+		switch result.Error {
+		case "":
+			return result, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval = min(interval+5*time.Second, _deviceCodeMaxInterval)
+		case "authorization_declined", "access_denied":
+			return nil, ErrorDeviceCodeDeclined
+		case "expired_token":
+			return nil, ErrorDeviceCodeExpired
+		default:
+			return nil, fmt.Errorf("device code login failed: %s: %s", result.Error, result.ErrorDescription)
+		}
+	}
+}
+
+// CompleteDeviceCodeLogin waits for the device code to be redeemed and finishes the Minecraft login with it.
+func CompleteDeviceCodeLogin(ctx context.Context, clientID string, code *DeviceCodeResponse) (*CompleteLoginResponse, error) {
+	tokenResp, err := PollDeviceCodeToken(ctx, clientID, code)
+	if err != nil {
+		return nil, err
+	}
+	return completeMinecraftLogin(tokenResp)
+}
@@ -0,0 +1,197 @@
+package minecraft
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrorEntitlementsKeyInvalid       = errors.New("entitlements public key is not an RSA public key")
+	ErrorEntitlementsSignatureInvalid = errors.New("entitlements signature is invalid")
+)
+
+// _bundledEntitlementsKey is Mojang's PEM encoded key for the signature of /entitlements/mcstore.
+//
+//go:embed mojang_entitlements.pem
+var _bundledEntitlementsKey []byte
+
+var (
+	_entitlementsKeyMu sync.RWMutex
+	// _entitlementsPublicKey verifies the signed store response. Without a key ownership can only be read
+	// from the unsigned item list.
+	_entitlementsPublicKey = getBundledEntitlementsPublicKey()
+)
+
+var (
+	_javaEditionEntitlements = []string{"product_minecraft", "game_minecraft"}
+	_gamePassEntitlements    = []string{"product_game_pass_pc", "product_game_pass_ultimate"}
+)
+
+type MinecraftOwnership struct {
+	OwnsJavaEdition bool     `json:"ownsJavaEdition"`
+	GamePass        bool     `json:"gamePass"`
+	Verified        bool     `json:"verified"` // the signed entitlements were checked against the configured key
+	Entitlements    []string `json:"entitlements"`
+}
+
+// CanPlay reports whether the account may play the full game rather than the demo.
+func (o MinecraftOwnership) CanPlay() bool {
+	return o.OwnsJavaEdition || o.GamePass
+}
+
+func getBundledEntitlementsPublicKey() *rsa.PublicKey {
+	key, err := parseEntitlementsPublicKey(_bundledEntitlementsKey)
+	if err != nil {
+		return nil
+	}
+	return key
+}
+
+// setEntitlementsPublicKey replaces the bundled key, so tests can sign store responses with their own key.
+// It returns a function that restores the previous key.
+func setEntitlementsPublicKey(key *rsa.PublicKey) func() {
+	_entitlementsKeyMu.Lock()
+	defer _entitlementsKeyMu.Unlock()
+
+	previous := _entitlementsPublicKey
+	_entitlementsPublicKey = key
+	return func() {
+		_entitlementsKeyMu.Lock()
+		defer _entitlementsKeyMu.Unlock()
+
+		_entitlementsPublicKey = previous
+	}
+}
+
+func getEntitlementsPublicKey() *rsa.PublicKey {
+	_entitlementsKeyMu.RLock()
+	defer _entitlementsKeyMu.RUnlock()
+
+	return _entitlementsPublicKey
+}
+
+// parseEntitlementsPublicKey reads a PEM encoded PKIX or PKCS#1 RSA public key.
+func parseEntitlementsPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrorEntitlementsKeyInvalid
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		if rsaKey, ok := key.(*rsa.PublicKey); ok {
+			return rsaKey, nil
+		}
+		return nil, ErrorEntitlementsKeyInvalid
+	}
+	return x509.ParsePKCS1PublicKey(block.Bytes)
+}
+
+type entitlementsClaims struct {
+	Entitlements []struct {
+		Name string `json:"name"`
+	} `json:"entitlements"`
+	Expires int64 `json:"exp"`
+}
+
+// verifyEntitlementsJWT checks an RS256 signature and returns the entitlement names from the signed claims.
+func verifyEntitlementsJWT(token string, key *rsa.PublicKey) ([]string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrorEntitlementsSignatureInvalid
+	}
+
+	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrorEntitlementsSignatureInvalid
+	}
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerData, &header); err != nil || header.Algorithm != "RS256" {
+		return nil, ErrorEntitlementsSignatureInvalid
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrorEntitlementsSignatureInvalid
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+		return nil, ErrorEntitlementsSignatureInvalid
+	}
+
+	claimsData, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrorEntitlementsSignatureInvalid
+	}
+	var claims entitlementsClaims
+	if err := json.Unmarshal(claimsData, &claims); err != nil {
+		return nil, ErrorEntitlementsSignatureInvalid
+	}
+	if claims.Expires != 0 && time.Now().Unix() > claims.Expires {
+		return nil, ErrorEntitlementsSignatureInvalid
+	}
+
+	names := make([]string, 0, len(claims.Entitlements))
+	for _, entitlement := range claims.Entitlements {
+		names = append(names, entitlement.Name)
+	}
+	return names, nil
+}
+
+func hasAnyEntitlement(names, wanted []string) bool {
+	for _, name := range names {
+		for _, w := range wanted {
+			if name == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VerifyStoreEntitlements decides ownership from a store response. With a key the signed claims are
+// authoritative and a bad signature is an error; without one the item list is trusted as-is.
+func VerifyStoreEntitlements(store *MinecraftStoreResponse, key *rsa.PublicKey) (MinecraftOwnership, error) {
+	var ownership MinecraftOwnership
+	if store == nil {
+		return ownership, nil
+	}
+
+	names := make([]string, 0, len(store.Items))
+	for _, item := range store.Items {
+		names = append(names, item.Name)
+	}
+
+	if key != nil {
+		signed, err := verifyEntitlementsJWT(store.Signature, key)
+		if err != nil {
+			return ownership, err
+		}
+		names = signed
+		ownership.Verified = true
+	}
+
+	ownership.Entitlements = names
+	ownership.OwnsJavaEdition = hasAnyEntitlement(names, _javaEditionEntitlements)
+	ownership.GamePass = hasAnyEntitlement(names, _gamePassEntitlements)
+	return ownership, nil
+}
+
+func GetMinecraftOwnership(accessToken string) (MinecraftOwnership, error) {
+	store, err := GetStoreInformation(accessToken)
+	if err != nil {
+		return MinecraftOwnership{}, err
+	}
+	return VerifyStoreEntitlements(store, getEntitlementsPublicKey())
+}
@@ -0,0 +1,212 @@
+package minecraft
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestEntitlementsKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// signTestEntitlements builds an RS256 JWT like the signature field of /entitlements/mcstore.
+func signTestEntitlements(t *testing.T, key *rsa.PrivateKey, names []string, expires time.Time) string {
+	t.Helper()
+
+	claims := map[string]any{
+		"entitlements": []map[string]string{},
+		"signerId":     "2535416586892404",
+		"nbf":          time.Now().Add(-time.Hour).Unix(),
+		"exp":          expires.Unix(),
+		"iat":          time.Now().Unix(),
+	}
+	for _, name := range names {
+		claims["entitlements"] = append(claims["entitlements"].([]map[string]string), map[string]string{"name": name})
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"1","alg":"RS256"}`))
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifyEntitlementsJWT(t *testing.T) {
+	key := newTestEntitlementsKey(t)
+	otherKey := newTestEntitlementsKey(t)
+	names := []string{"product_minecraft", "game_minecraft"}
+	valid := signTestEntitlements(t, key, names, time.Now().Add(time.Hour))
+
+	parts := strings.Split(valid, ".")
+	forged, _ := json.Marshal(map[string]any{
+		"entitlements": []map[string]string{{"name": "product_minecraft"}, {"name": "product_game_pass_ultimate"}},
+		"exp":          time.Now().Add(time.Hour).Unix(),
+	})
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2]
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+
+	tests := []struct {
+		name  string
+		token string
+		want  []string
+	}{
+		{"valid", valid, names},
+		{"tampered claims", tampered, nil},
+		{"expired", signTestEntitlements(t, key, names, time.Now().Add(-time.Minute)), nil},
+		{"signed with another key", signTestEntitlements(t, otherKey, names, time.Now().Add(time.Hour)), nil},
+		{"alg none", unsigned, nil},
+		{"not a JWT", "not-a-jwt", nil},
+		{"empty", "", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := verifyEntitlementsJWT(test.token, &key.PublicKey)
+			if test.want == nil {
+				if !errors.Is(err, ErrorEntitlementsSignatureInvalid) {
+					t.Fatalf("verifyEntitlementsJWT = %v, %v; want ErrorEntitlementsSignatureInvalid", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyEntitlementsJWT: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("verifyEntitlementsJWT = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestVerifyStoreEntitlements(t *testing.T) {
+	key := newTestEntitlementsKey(t)
+	restore := setEntitlementsPublicKey(&key.PublicKey)
+	defer restore()
+
+	store := func(items []string, signature string) *MinecraftStoreResponse {
+		var resp MinecraftStoreResponse
+		for _, name := range items {
+			resp.Items = append(resp.Items, MinecraftStoreItem{Name: name})
+		}
+		resp.Signature = signature
+		return &resp
+	}
+	hour := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		store   *MinecraftStoreResponse
+		want    MinecraftOwnership
+		wantErr bool
+	}{
+		{
+			name:  "owns java edition",
+			store: store([]string{"product_minecraft", "game_minecraft"}, signTestEntitlements(t, key, []string{"product_minecraft", "game_minecraft"}, hour)),
+			want:  MinecraftOwnership{OwnsJavaEdition: true, Verified: true, Entitlements: []string{"product_minecraft", "game_minecraft"}},
+		},
+		{
+			name:  "game pass",
+			store: store(nil, signTestEntitlements(t, key, []string{"product_game_pass_pc"}, hour)),
+			want:  MinecraftOwnership{GamePass: true, Verified: true, Entitlements: []string{"product_game_pass_pc"}},
+		},
+		{
+			name:  "unsigned items are ignored",
+			store: store([]string{"product_minecraft"}, signTestEntitlements(t, key, nil, hour)),
+			want:  MinecraftOwnership{Verified: true, Entitlements: []string{}},
+		},
+		{
+			name:    "tampered signature",
+			store:   store([]string{"product_minecraft"}, signTestEntitlements(t, key, []string{"product_minecraft"}, hour)+"x"),
+			wantErr: true,
+		},
+		{
+			name:    "expired signature",
+			store:   store([]string{"product_minecraft"}, signTestEntitlements(t, key, []string{"product_minecraft"}, time.Now().Add(-time.Hour))),
+			wantErr: true,
+		},
+		{
+			name:  "no store response",
+			store: nil,
+			want:  MinecraftOwnership{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := VerifyStoreEntitlements(test.store, getEntitlementsPublicKey())
+			if test.wantErr {
+				if !errors.Is(err, ErrorEntitlementsSignatureInvalid) {
+					t.Fatalf("VerifyStoreEntitlements = %+v, %v; want ErrorEntitlementsSignatureInvalid", got, err)
+				}
+				if got.CanPlay() {
+					t.Error("a store response with a bad signature can play")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyStoreEntitlements: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("VerifyStoreEntitlements = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseEntitlementsPublicKey(t *testing.T) {
+	key := newTestEntitlementsKey(t)
+	pkix, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encodings := map[string][]byte{
+		"PKIX":   pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}),
+		"PKCS#1": pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)}),
+	}
+	for name, data := range encodings {
+		parsed, err := parseEntitlementsPublicKey(data)
+		if err != nil {
+			t.Fatalf("parseEntitlementsPublicKey(%s): %v", name, err)
+		}
+		if !parsed.Equal(&key.PublicKey) {
+			t.Errorf("parseEntitlementsPublicKey(%s) returned a different key", name)
+		}
+	}
+
+	if _, err := parseEntitlementsPublicKey([]byte("no key here")); !errors.Is(err, ErrorEntitlementsKeyInvalid) {
+		t.Errorf("parseEntitlementsPublicKey without a PEM block = %v, want ErrorEntitlementsKeyInvalid", err)
+	}
+}
+
+func TestBundledEntitlementsKey(t *testing.T) {
+	if block, _ := pem.Decode(_bundledEntitlementsKey); block == nil {
+		t.Skip("no entitlements key is bundled")
+	}
+	if _, err := parseEntitlementsPublicKey(_bundledEntitlementsKey); err != nil {
+		t.Fatalf("bundled entitlements key: %v", err)
+	}
+}
@@ -1,5 +1,103 @@
 package minecraft
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
-var ErrorVersionNotFound error = errors.New("version no found")
\ No newline at end of file
+var ErrorVersionNotFound error = errors.New("version no found")
+
+// AuthError is a login failure the user can do something about. Key selects the translated message in the
+// frontend, Message is the English fallback and HelpURL points at the page that fixes the problem.
+type AuthError struct {
+	Code    string `json:"code"`
+	Key     string `json:"key"`
+	Message string `json:"message"`
+	HelpURL string `json:"helpUrl,omitempty"`
+	XErr    int64  `json:"xerr,omitempty"`
+	Status  int    `json:"status,omitempty"`
+	Details string `json:"details,omitempty"`
+}
+
+func (e *AuthError) Error() string {
+	if e.Details != "" {
+		return fmt.Sprintf("%s (%s)", e.Message, e.Details)
+	}
+	return e.Message
+}
+
+// Is matches on Code, so errors.Is(err, ErrChildAccount) holds for copies carrying request details.
+func (e *AuthError) Is(target error) bool {
+	t, ok := target.(*AuthError)
+	return ok && t.Code == e.Code
+}
+
+func (e *AuthError) with(status int, details string) *AuthError {
+	clone := *e
+	clone.Status = status
+	clone.Details = details
+	return &clone
+}
+
+func newAuthError(code, message, helpURL string) *AuthError {
+	return &AuthError{
+		Code:    code,
+		Key:     "auth.error." + code,
+		Message: message,
+		HelpURL: helpURL,
+	}
+}
+
+var (
+	ErrNoXboxAccount             = newAuthError("noXboxAccount", "This Microsoft account has no Xbox profile. Sign in at xbox.com once to create one.", "https://www.xbox.com/live")
+	ErrXboxAccountBanned         = newAuthError("xboxAccountBanned", "This Xbox account is banned.", "https://enforcement.xbox.com/")
+	ErrXboxAccountRestricted     = newAuthError("xboxAccountRestricted", "Online play is restricted for this account by its family settings.", "https://account.microsoft.com/family/")
+	ErrCountryNotSupported       = newAuthError("countryNotSupported", "Xbox Live is not available in this account's country.", "https://www.xbox.com/regions")
+	ErrAdultVerificationRequired = newAuthError("adultVerificationRequired", "This account needs adult verification before it can sign in.", "https://account.xbox.com/")
+	ErrChildAccount              = newAuthError("childAccount", "This is a child account. An adult must add it to a Microsoft family before it can sign in.", "https://account.microsoft.com/family/")
+	ErrMicrosoftAuthFailed       = newAuthError("microsoftAuthFailed", "Microsoft sign-in failed.", "https://account.microsoft.com/")
+	ErrLoginTimedOut             = newAuthError("loginTimedOut", "Sign-in took too long and was cancelled. Try again.", "")
+	ErrXboxAuthFailed            = newAuthError("xboxAuthFailed", "Xbox Live sign-in failed.", "https://support.xbox.com/")
+
+	ErrAzureAppNotPermitted = newAuthError("azureAppNotPermitted", "This launcher's Azure application is not allowed to use Minecraft Services yet.", "https://aka.ms/mce-reviewappid")
+	ErrGameNotOwned         = newAuthError("gameNotOwned", "This account does not own Minecraft: Java Edition.", "https://www.minecraft.net/store/minecraft-java-bedrock-edition-pc")
+	ErrProfileNotFound      = newAuthError("profileNotFound", "This account owns Minecraft but has not created a profile yet. Pick a name on minecraft.net first.", "https://www.minecraft.net/msaprofile/mygames/editprofile")
+	ErrTooManyRequests      = newAuthError("tooManyRequests", "Minecraft Services are rate limiting sign-ins. Try again in a few minutes.", "")
+	ErrMinecraftAuthFailed  = newAuthError("minecraftAuthFailed", "Minecraft Services sign-in failed.", "https://help.minecraft.net/")
+	ErrInvalidRefreshToken  = newAuthError("invalidRefreshToken", "The saved sign-in has expired. Sign in again.", "")
+	ErrAccessTokenExpired   = newAuthError("accessTokenExpired", "The Minecraft session has expired.", "")
+)
+
+// _xstsErrors maps the documented XSTS XErr codes.
+var _xstsErrors = map[int64]*AuthError{
+	2148916227: ErrXboxAccountBanned,
+	2148916229: ErrXboxAccountRestricted,
+	2148916233: ErrNoXboxAccount,
+	2148916235: ErrCountryNotSupported,
+	2148916236: ErrAdultVerificationRequired,
+	2148916237: ErrAdultVerificationRequired,
+	2148916238: ErrChildAccount,
+}
+
+func getXSTSError(xerr int64, status int, details string) *AuthError {
+	base, ok := _xstsErrors[xerr]
+	if !ok {
+		base = ErrXboxAuthFailed
+	}
+	err := base.with(status, details)
+	err.XErr = xerr
+	return err
+}
+
+// AsAuthError returns err as an AuthError, wrapping unexpected errors so callers can always send structured data.
+func AsAuthError(err error) *AuthError {
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return authErr
+	}
+	return &AuthError{
+		Code:    "unknown",
+		Key:     "auth.error.unknown",
+		Message: err.Error(),
+	}
+}
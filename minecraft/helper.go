@@ -1,10 +1,13 @@
 package minecraft
 
 import (
+	"archive/zip"
 	"crypto/sha1"
+	"crypto/sha512"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"os"
@@ -14,19 +17,25 @@ import (
 	"runtime"
 	"strings"
 
-	"golang.org/x/sys/windows"
 
 	"github.com/ulikunitz/xz/lzma"
 )
 
 func getSHA1Hash(path string) (string, error) {
+	return getFileHash(path, sha1.New())
+}
+
+func getSHA512Hash(path string) (string, error) {
+	return getFileHash(path, sha512.New())
+}
+
+func getFileHash(path string, hash hash.Hash) (string, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return "", err
 	}
 	defer file.Close()
 
-	hash := sha1.New()
 	_, err = io.Copy(hash, file)
 	if err != nil {
 		return "", err
@@ -35,6 +44,17 @@ func getSHA1Hash(path string) (string, error) {
 	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
 
+func getCallback(callback *Callback) *Callback {
+	if callback == nil {
+		return &Callback{
+			Progress: func(message string) {},
+			Max: func(message string) {},
+			Status: func(message string) {},
+		}
+	}
+	return callback
+}
+
 func checkPathInsideMinecraftDirectory(minecraftDir, path string) error {
 	absMinecraftDir, err := filepath.Abs(minecraftDir)
 	if err != nil {
@@ -184,8 +204,7 @@ func downloadCompressedFile(url, path, minecraftDir string, sha1Hash string, ove
 
 func getOSVersion() string {
 	if runtime.GOOS == "windows" {
-		maj, min, _ := windows.RtlGetNtVersionNumbers()		
-		return fmt.Sprintf("%d.%d", maj, min)
+		return getWindowsVersion()
 	}
 
 	if runtime.GOOS == "darwin" {		
@@ -356,11 +375,17 @@ func inheritJson(originalData ClientJson, path string) (ClientJson, error) {
 			libList = append(libList, lib)
 		}		
 	}
-	newData.Libraries = libList
+	newData.Libraries = append(originalData.Libraries, libList...)
 
 	if originalData.Arguments != nil && newData.Arguments != nil {
 		newData.Arguments.Game = append(newData.Arguments.Game, originalData.Arguments.Game...)
 		newData.Arguments.Jvm = append(newData.Arguments.Jvm, originalData.Arguments.Jvm...)
+	} else if originalData.Arguments != nil {
+		newData.Arguments = originalData.Arguments
+	}
+
+	if originalData.MinecraftArguments != "" {
+		newData.MinecraftArguments = originalData.MinecraftArguments
 	}
 
 	if originalData.Downloads.Client != (clientJsonDownloads{}) {
@@ -370,10 +395,13 @@ func inheritJson(originalData ClientJson, path string) (ClientJson, error) {
 		newData.Downloads.Server = originalData.Downloads.Server
 	}
 
-	if originalData.Logging.Client != (clientJsonLogging{}) {
-		newData.Logging.Client = originalData.Logging.Client
+	if originalData.Logging != nil && originalData.Logging.Client != (clientJsonLogging{}) {
+		newData.Logging = originalData.Logging
 	}
 
+	newData.Id = originalData.Id
+	newData.InheritsFrom = inheritVersion
+
 	if originalData.MainClass != "" {
 		newData.MainClass = originalData.MainClass
 	}
@@ -570,6 +598,83 @@ func readJSON[T any](path string) (T, error) {
 	return data, nil
 }
 
+func extractZipDirectory(r *zip.Reader, prefix, dest string) (int, error) {
+	extracted := 0
+	for _, f := range r.File {
+		if !strings.HasPrefix(f.Name, prefix) || f.Name == prefix {
+			continue
+		}
+
+		fullPath := filepath.Join(dest, filepath.FromSlash(strings.TrimPrefix(f.Name, prefix)))
+		if !strings.HasPrefix(fullPath, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return extracted, fmt.Errorf("invalid file path: %s", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(fullPath, 0755); err != nil {
+				return extracted, err
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return extracted, err
+		}
+
+		if err := extractZipFile(f, fullPath); err != nil {
+			return extracted, err
+		}
+		extracted++
+	}
+
+	return extracted, nil
+}
+
+func extractZipFile(f *zip.File, path string) error {
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func addFileToZip(w *zip.Writer, path, name string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	stat, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(stat)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	dst, err := w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
 func derefStr(ptr *string) string {
 	if ptr == nil {
 		return ""
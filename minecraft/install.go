@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -20,6 +21,16 @@ func downloadLibrary(id string, lib ClientJsonLibrary, mcDir string) error {
 	if lib.Downloads.Artifact != nil {
 		downloadURL = lib.Downloads.Artifact.Url
 		libPath = filepath.Join(currentPath, lib.Downloads.Artifact.Path)
+	} else {
+		libPath = getLibraryPath(lib.Name, mcDir)
+		relPath, err := filepath.Rel(currentPath, libPath)
+		if err != nil {
+			return err
+		}
+		if lib.Url != nil && *lib.Url != "" {
+			downloadURL = *lib.Url
+		}
+		downloadURL = strings.TrimSuffix(downloadURL, "/") + "/" + filepath.ToSlash(relPath)
 	}
 
 	native := getNatives(lib)
@@ -159,7 +170,7 @@ func installAssets(data ClientJson, mcDir string, callback Callback) error {
 }
 
 func doVersionInstall(versionID string, url, sha1 string, options MinecraftOptions, callback Callback) error {
-	mcDir := options.GameDirectory
+	mcDir := getInstallDirectory(options)
 	versionDir := filepath.Join(mcDir, "versions", versionID)
 	versionJsonPath := filepath.Join(versionDir, versionID+".json")
 
@@ -197,7 +208,7 @@ func doVersionInstall(versionID string, url, sha1 string, options MinecraftOptio
 		return fmt.Errorf("error while installing assets: %w", err)
 	}
 
-	if versionData.Logging.Client.File.Url != "" {
+	if versionData.Logging != nil && versionData.Logging.Client.File.Url != "" {
 		logFilePath := filepath.Join(mcDir, "assets", "log_configs", versionData.Logging.Client.File.Id)
 		if err := downloadFile(versionData.Logging.Client.File.Url, logFilePath, "", versionData.Logging.Client.File.Sha1, false); err != nil {
 			return fmt.Errorf("error download log config: %w", err)
@@ -231,19 +242,23 @@ func doVersionInstall(versionID string, url, sha1 string, options MinecraftOptio
 }
 
 func InstallMinecraftVersion(versionId string, options MinecraftOptions, callback *Callback) error {
-	versionList, err := fetch[VersionListManifestJson]("https://launchermeta.mojang.com/mc/game/version_manifest_v2.json")
-	if err != nil {
-		return fmt.Errorf("failed to decode version list: %w", err)
-	}
+	callback = getCallback(callback)
 
-	if callback == nil {
-		callback = &Callback{
-			Progress: func(message string) {},
-			Max: func(message string) {},
-			Status: func(message string) {},
+	localJsonPath := filepath.Join(getInstallDirectory(options), "versions", versionId, versionId+".json")
+	if fileExists(localJsonPath) {
+		if _, err := readJSON[ClientJson](localJsonPath); err == nil {
+			err := doVersionInstall(versionId, "", "", options, *callback)
+			if err != nil {
+				return fmt.Errorf("failed to install version %s: %w", versionId, err)
+			}
+			return nil
 		}
 	}
 
+	versionList, err := fetch[VersionListManifestJson]("https://launchermeta.mojang.com/mc/game/version_manifest_v2.json")
+	if err != nil {
+		return fmt.Errorf("failed to decode version list: %w", err)
+	}
 
 	for _, version := range versionList.Versions {
 		if version.Id == versionId {
@@ -0,0 +1,163 @@
+package minecraft
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	instanceManifestName   = "instance.json"
+	instanceGamePrefix     = "minecraft/"
+	instanceManifestFormat = 1
+)
+
+var ErrorInvalidInstanceArchive error = errors.New("invalid instance archive")
+
+var DefaultInstanceExportExclude = []string{
+	"versions",
+	"libraries",
+	"assets",
+	"runtime",
+	"natives",
+	"logs",
+	"crash-reports",
+	"screenshots",
+	".fabric",
+	".mixin.out",
+	"mods/" + modUpdateStagingDirectory,
+	"mods/" + modUpdateBackupDirectory,
+	"mods/" + safeModeFile,
+	"launcher_profiles.json",
+	"usercache.json",
+	"usernamecache.json",
+}
+
+func ExportInstanceArchive(gameDir, outputPath string, manifest InstanceManifest, options InstanceExportOptions) error {
+	if options.Exclude == nil {
+		options.Exclude = DefaultInstanceExportExclude
+	}
+	manifest.FormatVersion = instanceManifestFormat
+
+	tmpPath := outputPath + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	err = writeInstanceArchive(out, gameDir, outputPath, manifest, options)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, outputPath)
+}
+
+func writeInstanceArchive(out *os.File, gameDir, outputPath string, manifest InstanceManifest, options InstanceExportOptions) error {
+	w := zip.NewWriter(out)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	mw, err := w.Create(instanceManifestName)
+	if err != nil {
+		return err
+	}
+	if _, err := mw.Write(manifestData); err != nil {
+		return err
+	}
+
+	outputAbs, _ := filepath.Abs(outputPath)
+	err = filepath.WalkDir(gameDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(gameDir, path)
+		if err != nil || relPath == "." {
+			return err
+		}
+		if isMrpackExportExcluded(relPath, options.Exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		if abs, _ := filepath.Abs(path); abs == outputAbs || abs == outputAbs+".tmp" {
+			return nil
+		}
+
+		return addFileToZip(w, path, instanceGamePrefix+filepath.ToSlash(relPath))
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+func readInstanceManifest(r *zip.Reader) (InstanceManifest, error) {
+	var manifest InstanceManifest
+
+	f, err := r.Open(instanceManifestName)
+	if err != nil {
+		return manifest, fmt.Errorf("%w: %s not found", ErrorInvalidInstanceArchive, instanceManifestName)
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("%w: %v", ErrorInvalidInstanceArchive, err)
+	}
+	if manifest.FormatVersion > instanceManifestFormat {
+		return manifest, fmt.Errorf("%w: unsupported format version %d", ErrorInvalidInstanceArchive, manifest.FormatVersion)
+	}
+	if manifest.MinecraftVersion == "" && manifest.VersionId == "" {
+		return manifest, fmt.Errorf("%w: no version", ErrorInvalidInstanceArchive)
+	}
+
+	return manifest, nil
+}
+
+func GetInstanceArchiveInformation(path string) (InstanceManifest, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return InstanceManifest{}, err
+	}
+	defer r.Close()
+
+	return readInstanceManifest(&r.Reader)
+}
+
+func ExtractInstanceArchive(path, gameDir string) (InstanceManifest, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return InstanceManifest{}, err
+	}
+	defer r.Close()
+
+	manifest, err := readInstanceManifest(&r.Reader)
+	if err != nil {
+		return manifest, err
+	}
+
+	if err := os.MkdirAll(gameDir, 0755); err != nil {
+		return manifest, err
+	}
+	if _, err := extractZipDirectory(&r.Reader, instanceGamePrefix, gameDir); err != nil {
+		return manifest, fmt.Errorf("error extracting instance files: %w", err)
+	}
+
+	return manifest, nil
+}
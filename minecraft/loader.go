@@ -0,0 +1,274 @@
+package minecraft
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	LoaderVanilla  string = "vanilla"
+	LoaderFabric   string = "fabric"
+	LoaderQuilt    string = "quilt"
+	LoaderForge    string = "forge"
+	LoaderNeoForge string = "neoforge"
+)
+
+const (
+	_fabricMetaURL    = "https://meta.fabricmc.net/v2"
+	_quiltMetaURL     = "https://meta.quiltmc.org/v3"
+	_forgeMavenURL    = "https://maven.minecraftforge.net/net/minecraftforge/forge"
+	_neoforgeMavenURL = "https://maven.neoforged.net/releases/net/neoforged/neoforge"
+)
+
+var ErrorLoaderNotSupported error = errors.New("loader not supported")
+
+type loaderMetaVersion struct {
+	Loader struct {
+		Version string `json:"version"`
+		Stable  bool   `json:"stable"`
+	} `json:"loader"`
+}
+
+func getLoaderMetaURL(loader string) (string, error) {
+	switch loader {
+	case LoaderFabric:
+		return _fabricMetaURL, nil
+	case LoaderQuilt:
+		return _quiltMetaURL, nil
+	default:
+		return "", ErrorLoaderNotSupported
+	}
+}
+
+func GetLatestLoaderVersion(loader, minecraftVersion string) (string, error) {
+	metaURL, err := getLoaderMetaURL(loader)
+	if err != nil {
+		return "", err
+	}
+
+	versions, err := fetch[[]loaderMetaVersion](fmt.Sprintf("%s/versions/loader/%s", metaURL, url.PathEscape(minecraftVersion)))
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("no %s loader available for %s", loader, minecraftVersion)
+	}
+
+	for _, v := range versions {
+		if v.Loader.Stable {
+			return v.Loader.Version, nil
+		}
+	}
+	return versions[0].Loader.Version, nil
+}
+
+func installMetaLoader(loader, minecraftVersion, loaderVersion string, options MinecraftOptions, callback *Callback) (string, error) {
+	metaURL, err := getLoaderMetaURL(loader)
+	if err != nil {
+		return "", err
+	}
+
+	if loaderVersion == "" {
+		loaderVersion, err = GetLatestLoaderVersion(loader, minecraftVersion)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	profileURL := fmt.Sprintf("%s/versions/loader/%s/%s/profile/json", metaURL, url.PathEscape(minecraftVersion), url.PathEscape(loaderVersion))
+	profile, err := getRequestsResponseCache(profileURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s profile: %w", loader, err)
+	}
+
+	var versionData ClientJson
+	if err := json.Unmarshal(profile, &versionData); err != nil {
+		return "", err
+	}
+
+	versionDir := filepath.Join(getInstallDirectory(options), "versions", versionData.Id)
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(versionDir, versionData.Id+".json"), profile, 0644); err != nil {
+		return "", err
+	}
+
+	if err := InstallMinecraftVersion(versionData.Id, options, callback); err != nil {
+		return "", err
+	}
+
+	return versionData.Id, nil
+}
+
+func getForgeInstallerURL(loader, minecraftVersion, loaderVersion string) (string, string) {
+	if loader == LoaderNeoForge {
+		return fmt.Sprintf("%s/%s/neoforge-%s-installer.jar", _neoforgeMavenURL, loaderVersion, loaderVersion), "neoforge-" + loaderVersion
+	}
+
+	fullVersion := loaderVersion
+	if !strings.HasPrefix(loaderVersion, minecraftVersion+"-") {
+		fullVersion = minecraftVersion + "-" + loaderVersion
+	}
+	versionId := minecraftVersion + "-forge-" + strings.TrimPrefix(fullVersion, minecraftVersion+"-")
+	return fmt.Sprintf("%s/%s/forge-%s-installer.jar", _forgeMavenURL, fullVersion, fullVersion), versionId
+}
+
+func installForgeLoader(loader, minecraftVersion, loaderVersion string, options MinecraftOptions, callback *Callback) (string, error) {
+	if loaderVersion == "" {
+		return "", fmt.Errorf("%s requires an explicit loader version", loader)
+	}
+
+	mcDir := getInstallDirectory(options)
+	if err := InstallMinecraftVersion(minecraftVersion, options, callback); err != nil {
+		return "", err
+	}
+
+	installerURL, versionId := getForgeInstallerURL(loader, minecraftVersion, loaderVersion)
+	installerPath := filepath.Join(os.TempDir(), fmt.Sprintf("%s-%s-installer.jar", loader, loaderVersion))
+	defer os.Remove(installerPath)
+
+	callback.Status(fmt.Sprintf("Downloading %s installer...", loader))
+	if err := downloadFile(installerURL, installerPath, "", "", true); err != nil {
+		return "", fmt.Errorf("failed to download %s installer: %w", loader, err)
+	}
+
+	if !DoVanillaLauncherProfilesExist(mcDir) {
+		if err := os.WriteFile(filepath.Join(mcDir, "launcher_profiles.json"), []byte(`{"profiles":{}}`), 0644); err != nil {
+			return "", err
+		}
+	}
+
+	javaPath := options.ExecutablePath
+	if javaPath == "" {
+		if runtimeInfo, err := GetVersionRuntimeInformation(minecraftVersion, mcDir); err == nil && runtimeInfo != nil {
+			javaPath = GetExecutablePath(runtimeInfo.Name, mcDir)
+		}
+	}
+	if javaPath == "" {
+		javaPath = "java"
+	}
+
+	installFlag := "--installClient"
+	if loader == LoaderNeoForge {
+		installFlag = "--install-client"
+	}
+
+	callback.Status(fmt.Sprintf("Running %s installer...", loader))
+	cmd := exec.Command(javaPath, "-jar", installerPath, installFlag, mcDir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%s installer failed: %w: %s", loader, err, strings.TrimSpace(string(output)))
+	}
+
+	if err := InstallMinecraftVersion(versionId, options, callback); err != nil {
+		return "", err
+	}
+
+	return versionId, nil
+}
+
+func InstallLoader(loader, minecraftVersion, loaderVersion string, options MinecraftOptions, callback *Callback) (string, error) {
+	options.InstallDirectory = getInstallDirectory(options)
+
+	callback = getCallback(callback)
+
+	switch loader {
+	case "", LoaderVanilla:
+		return minecraftVersion, InstallMinecraftVersion(minecraftVersion, options, callback)
+	case LoaderFabric, LoaderQuilt:
+		return installMetaLoader(loader, minecraftVersion, loaderVersion, options, callback)
+	case LoaderForge, LoaderNeoForge:
+		return installForgeLoader(loader, minecraftVersion, loaderVersion, options, callback)
+	default:
+		return "", ErrorLoaderNotSupported
+	}
+}
+
+func getLibraryLoader(name string) (string, string) {
+	parts := strings.Split(name, ":")
+	if len(parts) < 3 {
+		return "", ""
+	}
+
+	switch parts[0] + ":" + parts[1] {
+	case "net.fabricmc:fabric-loader":
+		return LoaderFabric, parts[2]
+	case "org.quiltmc:quilt-loader":
+		return LoaderQuilt, parts[2]
+	case "net.neoforged:neoforge":
+		return LoaderNeoForge, parts[2]
+	case "net.minecraftforge:forge", "net.minecraftforge:fmlloader":
+		if _, version, found := strings.Cut(parts[2], "-"); found {
+			return LoaderForge, version
+		}
+		return LoaderForge, parts[2]
+	}
+	return "", ""
+}
+
+func getArgumentsLoader(data ClientJson) (string, string) {
+	if data.Arguments == nil {
+		return "", ""
+	}
+
+	for i, arg := range data.Arguments.Game {
+		value, ok := arg.(string)
+		if !ok || i+1 >= len(data.Arguments.Game) {
+			continue
+		}
+		next, _ := data.Arguments.Game[i+1].(string)
+		switch value {
+		case "--fml.neoForgeVersion":
+			return LoaderNeoForge, next
+		case "--fml.forgeVersion":
+			return LoaderForge, next
+		}
+	}
+	return "", ""
+}
+
+func GetVersionLoader(versionId, minecraftDir string) (VersionLoaderInfo, error) {
+	info := VersionLoaderInfo{
+		VersionId: versionId,
+		Loader:    LoaderVanilla,
+	}
+
+	visited := make(map[string]bool)
+	current := versionId
+	for current != "" {
+		if visited[current] {
+			return info, fmt.Errorf("inheritsFrom cycle detected at %s", current)
+		}
+		visited[current] = true
+
+		data, err := readJSON[ClientJson](filepath.Join(minecraftDir, "versions", current, current+".json"))
+		if err != nil {
+			return info, err
+		}
+
+		if info.Loader == LoaderVanilla {
+			for _, lib := range data.Libraries {
+				if loader, version := getLibraryLoader(lib.Name); loader != "" {
+					info.Loader, info.LoaderVersion = loader, version
+					break
+				}
+			}
+		}
+		if info.Loader == LoaderVanilla {
+			if loader, version := getArgumentsLoader(data); loader != "" {
+				info.Loader, info.LoaderVersion = loader, version
+			}
+		}
+
+		info.MinecraftVersion = current
+		current = data.InheritsFrom
+	}
+
+	return info, nil
+}
@@ -6,13 +6,18 @@ type MinecraftOptions struct {
 	Username              string   `json:"username,omitempty"`
 	Uuid                  string   `json:"uuid,omitempty"`
 	Token                 string   `json:"token,omitempty"`
+	UserType              string   `json:"userType,omitempty"` // UserTypeMSA or UserTypeLegacy; defaults to UserTypeMSA
 	ExecutablePath        string   `json:"executablePath,omitempty"`
 	DefaultExecutablePath string   `json:"defaultExecutablePath,omitempty"`
 	JvmArguments          []string `json:"jvmArguments,omitempty"`
 	LauncherName          string   `json:"launcherName,omitempty"`
 	LauncherVersion       string   `json:"launcherVersion,omitempty"`
 	GameDirectory         string   `json:"gameDirectory,omitempty"`
+	InstallDirectory      string   `json:"installDirectory,omitempty"` // shared versions, libraries, assets and runtimes; defaults to GameDirectory
 	Demo                  bool     `json:"demo,omitempty"`
+	AuthlibInjectorPath   string   `json:"authlibInjectorPath,omitempty"`
+	YggdrasilAPIURL       string   `json:"yggdrasilAPIURL,omitempty"`   // authlib-injector is only added when this and AuthlibInjectorPath are set
+	YggdrasilMetadata     string   `json:"yggdrasilMetadata,omitempty"` // prefetched server metadata, saves the game a request at startup
 	CustomResolution      bool     `json:"customResolution,omitempty"`
 	ResolutionWidth       string   `json:"resolutionWidth,omitempty"`
 	ResolutionHeight      string   `json:"resolutionHeight,omitempty"`
@@ -28,6 +33,16 @@ type MinecraftOptions struct {
 	QuickPlayRealms       *string  `json:"quickPlayRealms,omitempty"`
 }
 
+func getInstallDirectory(options MinecraftOptions) string {
+	if options.InstallDirectory != "" {
+		return options.InstallDirectory
+	}
+	if options.GameDirectory != "" {
+		return options.GameDirectory
+	}
+	return GetMinecraftDirectory()
+}
+
 type API interface {
 	InstallMinecraftVersion(versionId string) error
 
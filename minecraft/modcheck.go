@@ -0,0 +1,224 @@
+package minecraft
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	ModIssueError   string = "error"
+	ModIssueWarning string = "warning"
+)
+
+const (
+	ModIssueMissingDependency string = "missing_dependency"
+	ModIssueIncompatible      string = "incompatible"
+	ModIssueDuplicateId       string = "duplicate_id"
+	ModIssueMinecraftVersion  string = "minecraft_version"
+	ModIssueWrongLoader       string = "wrong_loader"
+	ModIssueInvalidMetadata   string = "invalid_metadata"
+)
+
+var builtinModIds = map[string][]string{
+	LoaderFabric:   {"fabricloader", "fabric-loader"},
+	LoaderQuilt:    {"quilt_loader", "fabricloader", "fabric-loader"},
+	LoaderForge:    {"forge", "fml", "mcp", "javafml", "lowcodefml"},
+	LoaderNeoForge: {"neoforge", "fml", "javafml", "lowcodefml"},
+}
+
+var compatibleModLoaders = map[string][]string{
+	LoaderFabric:   {LoaderFabric},
+	LoaderQuilt:    {LoaderQuilt, LoaderFabric},
+	LoaderForge:    {LoaderForge},
+	LoaderNeoForge: {LoaderNeoForge, LoaderForge},
+}
+
+func GetCompatibleModLoaders(loader string) []string {
+	if loaders, found := compatibleModLoaders[loader]; found {
+		return slices.Clone(loaders)
+	}
+	return []string{loader}
+}
+
+func HasBlockingModIssues(issues []ModIssue) bool {
+	return slices.ContainsFunc(issues, func(issue ModIssue) bool {
+		return issue.Severity == ModIssueError
+	})
+}
+
+func CheckMods(gameDir, versionId, minecraftDir string) ([]ModIssue, error) {
+	if minecraftDir == "" {
+		minecraftDir = gameDir
+	}
+
+	loader, err := GetVersionLoader(versionId, minecraftDir)
+	if err != nil {
+		return nil, err
+	}
+
+	mods, err := ListMods(gameDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return CheckModList(mods, loader), nil
+}
+
+func CheckModList(mods []ModInfo, loader VersionLoaderInfo) []ModIssue {
+	issues := []ModIssue{}
+
+	var enabled []ModInfo
+	for _, mod := range mods {
+		if mod.Enabled {
+			enabled = append(enabled, mod)
+		}
+	}
+	if len(enabled) == 0 {
+		return issues
+	}
+
+	if loader.Loader == LoaderVanilla || loader.Loader == "" {
+		for _, mod := range enabled {
+			issues = append(issues, ModIssue{
+				Severity: ModIssueWarning,
+				Type:     ModIssueWrongLoader,
+				ModId:    mod.Id,
+				FileName: mod.FileName,
+				Message:  fmt.Sprintf("%s will not be loaded: %s has no mod loader", mod.Name, loader.VersionId),
+			})
+		}
+		return issues
+	}
+
+	available := map[string]string{
+		"minecraft": loader.MinecraftVersion,
+		"java":      "",
+	}
+	for _, id := range builtinModIds[loader.Loader] {
+		available[id] = loader.LoaderVersion
+	}
+
+	owners := make(map[string][]ModInfo)
+	var loaded []ModInfo
+	for _, mod := range enabled {
+		if mod.Error != "" {
+			issues = append(issues, ModIssue{
+				Severity: ModIssueWarning,
+				Type:     ModIssueInvalidMetadata,
+				ModId:    mod.Id,
+				FileName: mod.FileName,
+				Message:  fmt.Sprintf("%s could not be read: %s", mod.FileName, mod.Error),
+			})
+			continue
+		}
+
+		if !slices.Contains(compatibleModLoaders[loader.Loader], mod.Loader) {
+			issues = append(issues, ModIssue{
+				Severity: ModIssueWarning,
+				Type:     ModIssueWrongLoader,
+				ModId:    mod.Id,
+				FileName: mod.FileName,
+				Related:  mod.Loader,
+				Message:  fmt.Sprintf("%s is built for %s, but %s uses %s", mod.Name, mod.Loader, loader.VersionId, loader.Loader),
+			})
+			continue
+		}
+
+		loaded = append(loaded, mod)
+		owners[mod.Id] = append(owners[mod.Id], mod)
+		if _, found := available[mod.Id]; !found {
+			available[mod.Id] = mod.Version
+		}
+		for _, provided := range mod.Provides {
+			if _, found := available[provided]; !found {
+				available[provided] = ""
+			}
+		}
+	}
+
+	for _, mod := range loaded {
+		if duplicates := owners[mod.Id]; len(duplicates) > 1 {
+			var files []string
+			for _, duplicate := range duplicates {
+				files = append(files, duplicate.FileName)
+			}
+			issues = append(issues, ModIssue{
+				Severity: ModIssueError,
+				Type:     ModIssueDuplicateId,
+				ModId:    mod.Id,
+				FileName: mod.FileName,
+				Message:  fmt.Sprintf("mod id %s is provided by multiple files: %s", mod.Id, strings.Join(files, ", ")),
+			})
+		}
+
+		if mod.MinecraftVersion != "" {
+			if matches, ok := VersionMatchesRange(loader.MinecraftVersion, mod.MinecraftVersion); ok && !matches {
+				issues = append(issues, ModIssue{
+					Severity: ModIssueError,
+					Type:     ModIssueMinecraftVersion,
+					ModId:    mod.Id,
+					FileName: mod.FileName,
+					Related:  mod.MinecraftVersion,
+					Message:  fmt.Sprintf("%s requires Minecraft %s, but %s is %s", mod.Name, mod.MinecraftVersion, loader.VersionId, loader.MinecraftVersion),
+				})
+			}
+		}
+
+		for _, dep := range mod.Dependencies {
+			version, present := available[dep.Id]
+
+			switch dep.Type {
+			case ModDependencyRequired:
+				if !present {
+					issues = append(issues, ModIssue{
+						Severity: ModIssueError,
+						Type:     ModIssueMissingDependency,
+						ModId:    mod.Id,
+						FileName: mod.FileName,
+						Related:  dep.Id,
+						Message:  fmt.Sprintf("%s requires %s %s, which is not installed", mod.Name, dep.Id, dep.VersionRange),
+					})
+					continue
+				}
+				if version == "" {
+					continue
+				}
+				if matches, ok := VersionMatchesRange(version, dep.VersionRange); ok && !matches {
+					issues = append(issues, ModIssue{
+						Severity: ModIssueError,
+						Type:     ModIssueMissingDependency,
+						ModId:    mod.Id,
+						FileName: mod.FileName,
+						Related:  dep.Id,
+						Message:  fmt.Sprintf("%s requires %s %s, but %s is installed", mod.Name, dep.Id, dep.VersionRange, version),
+					})
+				}
+			case ModDependencyBreaks, ModDependencyIncompatible, ModDependencyConflicts:
+				if !present || dep.Id == mod.Id {
+					continue
+				}
+				if version != "" {
+					if matches, ok := VersionMatchesRange(version, dep.VersionRange); ok && !matches {
+						continue
+					}
+				}
+
+				severity := ModIssueError
+				if dep.Type == ModDependencyConflicts {
+					severity = ModIssueWarning
+				}
+				issues = append(issues, ModIssue{
+					Severity: severity,
+					Type:     ModIssueIncompatible,
+					ModId:    mod.Id,
+					FileName: mod.FileName,
+					Related:  dep.Id,
+					Message:  fmt.Sprintf("%s is incompatible with %s %s", mod.Name, dep.Id, version),
+				})
+			}
+		}
+	}
+
+	return issues
+}
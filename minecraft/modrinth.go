@@ -0,0 +1,92 @@
+package minecraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const _modrinthAPIURL = "https://api.modrinth.com/v2"
+
+func modrinthRequest[T any](apiURL, method, path string, body any) (T, error) {
+	var result T
+
+	if apiURL == "" {
+		apiURL = _modrinthAPIURL
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return result, err
+		}
+		reqBody = bytes.NewBuffer(jsonBody)
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(apiURL, "/")+path, reqBody)
+	if err != nil {
+		return result, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", getUserAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("modrinth api returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, err
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, fmt.Errorf("failed to unmarshal JSON: %v", err)
+	}
+	return result, nil
+}
+
+func GetModrinthVersionsFromHashes(apiURL string, hashes []string, algorithm string) (map[string]ModrinthVersion, error) {
+	if len(hashes) == 0 {
+		return map[string]ModrinthVersion{}, nil
+	}
+
+	body := map[string]any{
+		"hashes":    hashes,
+		"algorithm": algorithm,
+	}
+	return modrinthRequest[map[string]ModrinthVersion](apiURL, "POST", "/version_files", body)
+}
+
+func NewModrinthFileLookup(apiURL string) MrpackFileLookup {
+	return func(sha1Hashes []string) (map[string]string, error) {
+		versions, err := GetModrinthVersionsFromHashes(apiURL, sha1Hashes, "sha1")
+		if err != nil {
+			return nil, err
+		}
+
+		urls := make(map[string]string, len(versions))
+		for hash, version := range versions {
+			for _, file := range version.Files {
+				if file.Hashes["sha1"] == hash {
+					urls[hash] = file.Url
+					break
+				}
+			}
+		}
+		return urls, nil
+	}
+}
@@ -0,0 +1,588 @@
+package minecraft
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ModDependencyRequired     string = "required"
+	ModDependencyOptional     string = "optional"
+	ModDependencyBreaks       string = "breaks"
+	ModDependencyIncompatible string = "incompatible"
+	ModDependencyConflicts    string = "conflicts"
+)
+
+const disabledSuffix = ".disabled"
+
+var ErrorModMetadataNotFound error = errors.New("mod metadata not found")
+
+type fabricModJson struct {
+	Id          string         `json:"id"`
+	Name        string         `json:"name"`
+	Version     string         `json:"version"`
+	Description string         `json:"description"`
+	Authors     []any          `json:"authors"`
+	Icon        any            `json:"icon"`
+	Provides    []string       `json:"provides"`
+	Depends     map[string]any `json:"depends"`
+	Recommends  map[string]any `json:"recommends"`
+	Suggests    map[string]any `json:"suggests"`
+	Breaks      map[string]any `json:"breaks"`
+	Conflicts   map[string]any `json:"conflicts"`
+}
+
+type quiltModJson struct {
+	QuiltLoader struct {
+		Id       string `json:"id"`
+		Version  string `json:"version"`
+		Provides []any  `json:"provides"`
+		Depends  []any  `json:"depends"`
+		Breaks   []any  `json:"breaks"`
+		Metadata struct {
+			Name         string            `json:"name"`
+			Description  string            `json:"description"`
+			Contributors map[string]string `json:"contributors"`
+			Icon         any               `json:"icon"`
+		} `json:"metadata"`
+	} `json:"quilt_loader"`
+}
+
+type mcmodInfoEntry struct {
+	ModId        string   `json:"modid"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Version      string   `json:"version"`
+	McVersion    string   `json:"mcversion"`
+	AuthorList   []string `json:"authorList"`
+	Authors      []string `json:"authors"`
+	LogoFile     string   `json:"logoFile"`
+	RequiredMods []string `json:"requiredMods"`
+	Dependencies []string `json:"dependencies"`
+}
+
+func ListMods(gameDir string) ([]ModInfo, error) {
+	modsDir := filepath.Join(gameDir, "mods")
+	entries, err := os.ReadDir(modsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ModInfo{}, nil
+		}
+		return nil, err
+	}
+
+	mods := []ModInfo{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		lower := strings.ToLower(name)
+		if !strings.HasSuffix(lower, ".jar") && !strings.HasSuffix(lower, ".jar"+disabledSuffix) {
+			continue
+		}
+
+		mods = append(mods, ReadModInfo(filepath.Join(modsDir, name)))
+	}
+
+	sort.Slice(mods, func(i, j int) bool {
+		return strings.ToLower(mods[i].FileName) < strings.ToLower(mods[j].FileName)
+	})
+
+	return mods, nil
+}
+
+func ReadModInfo(jarPath string) ModInfo {
+	fileName := filepath.Base(jarPath)
+	info := ModInfo{
+		FileName:     fileName,
+		Path:         jarPath,
+		Enabled:      !strings.HasSuffix(strings.ToLower(fileName), disabledSuffix),
+		Name:         strings.TrimSuffix(strings.TrimSuffix(fileName, disabledSuffix), ".jar"),
+		Authors:      []string{},
+		Dependencies: []ModDependency{},
+	}
+
+	r, err := zip.OpenReader(jarPath)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	defer r.Close()
+
+	readModMetadata(&r.Reader, &info)
+	return info
+}
+
+func readModMetadata(r *zip.Reader, info *ModInfo) {
+	parsers := []struct {
+		file  string
+		parse func(*zip.Reader, []byte, *ModInfo) error
+	}{
+		{"quilt.mod.json", parseQuiltModJson},
+		{"fabric.mod.json", parseFabricModJson},
+		{"META-INF/neoforge.mods.toml", parseModsToml},
+		{"META-INF/mods.toml", parseModsToml},
+		{"mcmod.info", parseMcmodInfo},
+	}
+
+	for _, parser := range parsers {
+		data, err := readZipEntry(r, parser.file)
+		if err != nil {
+			continue
+		}
+
+		if parser.file == "META-INF/neoforge.mods.toml" {
+			info.Loader = LoaderNeoForge
+		}
+		if err := parser.parse(r, data, info); err != nil {
+			info.Error = fmt.Sprintf("%s: %v", parser.file, err)
+			return
+		}
+
+		info.Provides = append(info.Provides, getNestedModIds(r)...)
+		return
+	}
+
+	info.Error = ErrorModMetadataNotFound.Error()
+}
+
+func getNestedJarPaths(r *zip.Reader) []string {
+	var paths []string
+
+	if data, err := readZipEntry(r, "fabric.mod.json"); err == nil {
+		var mod struct {
+			Jars []struct {
+				File string `json:"file"`
+			} `json:"jars"`
+		}
+		if json.Unmarshal(data, &mod) == nil {
+			for _, jar := range mod.Jars {
+				paths = append(paths, jar.File)
+			}
+		}
+	}
+
+	if data, err := readZipEntry(r, "META-INF/jarjar/metadata.json"); err == nil {
+		var metadata struct {
+			Jars []struct {
+				Path string `json:"path"`
+			} `json:"jars"`
+		}
+		if json.Unmarshal(data, &metadata) == nil {
+			for _, jar := range metadata.Jars {
+				paths = append(paths, jar.Path)
+			}
+		}
+	}
+
+	for _, f := range r.File {
+		if strings.HasPrefix(f.Name, "META-INF/jars/") && strings.HasSuffix(f.Name, ".jar") && !slices.Contains(paths, f.Name) {
+			paths = append(paths, f.Name)
+		}
+	}
+
+	return paths
+}
+
+func getNestedModIds(r *zip.Reader) []string {
+	var ids []string
+	for _, jarPath := range getNestedJarPaths(r) {
+		data, err := readZipEntry(r, path.Clean(strings.TrimPrefix(jarPath, "/")))
+		if err != nil {
+			continue
+		}
+
+		nested, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			continue
+		}
+
+		var nestedInfo ModInfo
+		readModMetadata(nested, &nestedInfo)
+		if nestedInfo.Id != "" {
+			ids = append(ids, nestedInfo.Id)
+		}
+		ids = append(ids, nestedInfo.Provides...)
+	}
+	return ids
+}
+
+func readZipEntry(r *zip.Reader, name string) ([]byte, error) {
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+func readModIcon(r *zip.Reader, iconPath string) []byte {
+	if iconPath == "" {
+		return nil
+	}
+
+	data, err := readZipEntry(r, path.Clean(strings.TrimPrefix(iconPath, "/")))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func getModVersionRange(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		var ranges []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				ranges = append(ranges, s)
+			}
+		}
+		return strings.Join(ranges, " || ")
+	}
+	return "*"
+}
+
+func addFabricDependencies(info *ModInfo, deps map[string]any, depType string) {
+	ids := make([]string, 0, len(deps))
+	for id := range deps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		versionRange := getModVersionRange(deps[id])
+		if id == "minecraft" {
+			if depType == ModDependencyRequired {
+				info.MinecraftVersion = versionRange
+			}
+			continue
+		}
+		info.Dependencies = append(info.Dependencies, ModDependency{
+			Id:           id,
+			VersionRange: versionRange,
+			Type:         depType,
+		})
+	}
+}
+
+func parseFabricModJson(r *zip.Reader, data []byte, info *ModInfo) error {
+	var mod fabricModJson
+	if err := json.Unmarshal(data, &mod); err != nil {
+		return err
+	}
+
+	info.Loader = LoaderFabric
+	info.Id = mod.Id
+	info.Version = mod.Version
+	info.Description = mod.Description
+	info.Provides = mod.Provides
+	if mod.Name != "" {
+		info.Name = mod.Name
+	} else if mod.Id != "" {
+		info.Name = mod.Id
+	}
+
+	for _, author := range mod.Authors {
+		switch a := author.(type) {
+		case string:
+			info.Authors = append(info.Authors, a)
+		case map[string]any:
+			if name, ok := a["name"].(string); ok {
+				info.Authors = append(info.Authors, name)
+			}
+		}
+	}
+
+	addFabricDependencies(info, mod.Depends, ModDependencyRequired)
+	addFabricDependencies(info, mod.Recommends, ModDependencyOptional)
+	addFabricDependencies(info, mod.Suggests, ModDependencyOptional)
+	addFabricDependencies(info, mod.Breaks, ModDependencyBreaks)
+	addFabricDependencies(info, mod.Conflicts, ModDependencyConflicts)
+
+	info.Icon = readModIcon(r, getLargestIcon(mod.Icon))
+	return nil
+}
+
+func getLargestIcon(icon any) string {
+	switch v := icon.(type) {
+	case string:
+		return v
+	case map[string]any:
+		largest := -1
+		var iconPath string
+		for size, value := range v {
+			s, err := strconv.Atoi(size)
+			if p, ok := value.(string); ok && err == nil && s > largest {
+				largest = s
+				iconPath = p
+			}
+		}
+		return iconPath
+	}
+	return ""
+}
+
+func addQuiltDependencies(info *ModInfo, deps []any, depType string) {
+	for _, dep := range deps {
+		var dependency ModDependency
+		dependency.Type = depType
+		dependency.VersionRange = "*"
+
+		switch d := dep.(type) {
+		case string:
+			dependency.Id = d
+		case map[string]any:
+			dependency.Id, _ = d["id"].(string)
+			if versions, found := d["versions"]; found {
+				dependency.VersionRange = getModVersionRange(versions)
+			}
+			if optional, _ := d["optional"].(bool); optional && depType == ModDependencyRequired {
+				dependency.Type = ModDependencyOptional
+			}
+		default:
+			continue
+		}
+
+		if dependency.Id == "minecraft" {
+			if dependency.Type == ModDependencyRequired {
+				info.MinecraftVersion = dependency.VersionRange
+			}
+			continue
+		}
+		info.Dependencies = append(info.Dependencies, dependency)
+	}
+}
+
+func parseQuiltModJson(r *zip.Reader, data []byte, info *ModInfo) error {
+	var mod quiltModJson
+	if err := json.Unmarshal(data, &mod); err != nil {
+		return err
+	}
+
+	loader := mod.QuiltLoader
+	info.Loader = LoaderQuilt
+	info.Id = loader.Id
+	info.Version = loader.Version
+	info.Description = loader.Metadata.Description
+	if loader.Metadata.Name != "" {
+		info.Name = loader.Metadata.Name
+	} else if loader.Id != "" {
+		info.Name = loader.Id
+	}
+
+	for name := range loader.Metadata.Contributors {
+		info.Authors = append(info.Authors, name)
+	}
+	sort.Strings(info.Authors)
+
+	for _, provided := range loader.Provides {
+		switch p := provided.(type) {
+		case string:
+			info.Provides = append(info.Provides, p)
+		case map[string]any:
+			if id, ok := p["id"].(string); ok {
+				info.Provides = append(info.Provides, id)
+			}
+		}
+	}
+
+	addQuiltDependencies(info, loader.Depends, ModDependencyRequired)
+	addQuiltDependencies(info, loader.Breaks, ModDependencyBreaks)
+
+	info.Icon = readModIcon(r, getLargestIcon(loader.Metadata.Icon))
+	return nil
+}
+
+func getJarManifestVersion(r *zip.Reader) string {
+	data, err := readZipEntry(r, "META-INF/MANIFEST.MF")
+	if err != nil {
+		return ""
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		if version, found := strings.CutPrefix(scanner.Text(), "Implementation-Version:"); found {
+			return strings.TrimSpace(version)
+		}
+	}
+	return ""
+}
+
+func tomlString(table map[string]any, key string) string {
+	value, _ := table[key].(string)
+	return value
+}
+
+func parseModsToml(r *zip.Reader, data []byte, info *ModInfo) error {
+	root, err := parseTOML(string(data))
+	if err != nil {
+		return err
+	}
+
+	mods, _ := root["mods"].([]any)
+	if len(mods) == 0 {
+		return errors.New("no mods declared")
+	}
+
+	if info.Loader == "" {
+		info.Loader = LoaderForge
+	}
+
+	for i, entry := range mods {
+		mod, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		modId := tomlString(mod, "modId")
+		if i > 0 {
+			info.Provides = append(info.Provides, modId)
+			continue
+		}
+
+		info.Id = modId
+		info.Version = tomlString(mod, "version")
+		if info.Version == "${file.jarVersion}" {
+			info.Version = getJarManifestVersion(r)
+		}
+		info.Description = strings.TrimSpace(tomlString(mod, "description"))
+		if name := tomlString(mod, "displayName"); name != "" {
+			info.Name = name
+		}
+
+		authors := tomlString(mod, "authors")
+		if authors == "" {
+			authors = tomlString(root, "authors")
+		}
+		for _, author := range strings.Split(authors, ",") {
+			if author = strings.TrimSpace(author); author != "" {
+				info.Authors = append(info.Authors, author)
+			}
+		}
+
+		logoFile := tomlString(mod, "logoFile")
+		if logoFile == "" {
+			logoFile = tomlString(root, "logoFile")
+		}
+		info.Icon = readModIcon(r, logoFile)
+	}
+
+	dependencies, _ := root["dependencies"].(map[string]any)
+	deps, _ := dependencies[info.Id].([]any)
+	for _, entry := range deps {
+		dep, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if side := tomlString(dep, "side"); side == "SERVER" {
+			continue
+		}
+
+		dependency := ModDependency{
+			Id:           tomlString(dep, "modId"),
+			VersionRange: tomlString(dep, "versionRange"),
+			Type:         ModDependencyOptional,
+		}
+
+		if mandatory, ok := dep["mandatory"].(bool); ok && mandatory {
+			dependency.Type = ModDependencyRequired
+		}
+		switch strings.ToLower(tomlString(dep, "type")) {
+		case "required":
+			dependency.Type = ModDependencyRequired
+		case "incompatible":
+			dependency.Type = ModDependencyIncompatible
+		case "discouraged":
+			dependency.Type = ModDependencyConflicts
+		}
+
+		switch dependency.Id {
+		case "minecraft":
+			info.MinecraftVersion = dependency.VersionRange
+			continue
+		case "neoforge":
+			info.Loader = LoaderNeoForge
+		}
+		info.Dependencies = append(info.Dependencies, dependency)
+	}
+
+	return nil
+}
+
+func parseMcmodInfo(r *zip.Reader, data []byte, info *ModInfo) error {
+	var entries []mcmodInfoEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		var wrapped struct {
+			ModList []mcmodInfoEntry `json:"modList"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return err
+		}
+		entries = wrapped.ModList
+	}
+	if len(entries) == 0 {
+		return errors.New("no mods declared")
+	}
+
+	mod := entries[0]
+	info.Loader = LoaderForge
+	info.Id = mod.ModId
+	info.Version = mod.Version
+	info.Description = mod.Description
+	info.MinecraftVersion = mod.McVersion
+	if mod.Name != "" {
+		info.Name = mod.Name
+	}
+
+	info.Authors = append(info.Authors, mod.AuthorList...)
+	info.Authors = append(info.Authors, mod.Authors...)
+
+	for _, entry := range entries[1:] {
+		info.Provides = append(info.Provides, entry.ModId)
+	}
+
+	for _, required := range mod.RequiredMods {
+		id, versionRange, _ := strings.Cut(required, "@")
+		if slices.ContainsFunc(info.Dependencies, func(d ModDependency) bool { return d.Id == id }) {
+			continue
+		}
+		info.Dependencies = append(info.Dependencies, ModDependency{
+			Id:           id,
+			VersionRange: versionRange,
+			Type:         ModDependencyRequired,
+		})
+	}
+
+	for _, optional := range mod.Dependencies {
+		id, versionRange, _ := strings.Cut(optional, "@")
+		if slices.ContainsFunc(info.Dependencies, func(d ModDependency) bool { return d.Id == id }) {
+			continue
+		}
+		info.Dependencies = append(info.Dependencies, ModDependency{
+			Id:           id,
+			VersionRange: versionRange,
+			Type:         ModDependencyOptional,
+		})
+	}
+
+	info.Icon = readModIcon(r, mod.LogoFile)
+	return nil
+}
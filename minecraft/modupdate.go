@@ -0,0 +1,306 @@
+package minecraft
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const _modrinthSiteURL = "https://modrinth.com"
+
+const (
+	modUpdateStagingDirectory = ".update-staging"
+	modUpdateBackupDirectory  = ".update-backup"
+)
+
+const (
+	ModDependencyAdded   string = "added"
+	ModDependencyRemoved string = "removed"
+	ModDependencyChanged string = "changed"
+)
+
+var ErrorModUpdateConflict error = errors.New("mod update conflicts with an existing file")
+
+func getModrinthUpdates(apiURL string, hashes []string, algorithm string, loaders, gameVersions []string) (map[string]ModrinthVersion, error) {
+	if len(hashes) == 0 {
+		return map[string]ModrinthVersion{}, nil
+	}
+
+	body := map[string]any{
+		"hashes":        hashes,
+		"algorithm":     algorithm,
+		"loaders":       loaders,
+		"game_versions": gameVersions,
+	}
+	return modrinthRequest[map[string]ModrinthVersion](apiURL, "POST", "/version_files/update", body)
+}
+
+func getModrinthPrimaryFile(version ModrinthVersion) (ModrinthVersionFile, bool) {
+	for _, file := range version.Files {
+		if file.Primary {
+			return file, true
+		}
+	}
+	if len(version.Files) > 0 {
+		return version.Files[0], true
+	}
+	return ModrinthVersionFile{}, false
+}
+
+func getModrinthDependencyKey(dependency ModrinthVersionDependency) string {
+	switch {
+	case dependency.ProjectId != nil && *dependency.ProjectId != "":
+		return "project:" + *dependency.ProjectId
+	case dependency.VersionId != nil && *dependency.VersionId != "":
+		return "version:" + *dependency.VersionId
+	case dependency.FileName != nil:
+		return "file:" + *dependency.FileName
+	}
+	return ""
+}
+
+func toModUpdateDependencyChange(dependency ModrinthVersionDependency, change string) ModUpdateDependencyChange {
+	result := ModUpdateDependencyChange{
+		DependencyType: dependency.DependencyType,
+		Change:         change,
+	}
+	if dependency.ProjectId != nil {
+		result.ProjectId = *dependency.ProjectId
+	}
+	if dependency.VersionId != nil {
+		result.VersionId = *dependency.VersionId
+	}
+	if dependency.FileName != nil {
+		result.FileName = *dependency.FileName
+	}
+	return result
+}
+
+func getModUpdateDependencyChanges(oldDependencies, newDependencies []ModrinthVersionDependency) []ModUpdateDependencyChange {
+	oldMap := make(map[string]ModrinthVersionDependency, len(oldDependencies))
+	for _, dependency := range oldDependencies {
+		oldMap[getModrinthDependencyKey(dependency)] = dependency
+	}
+
+	changes := []ModUpdateDependencyChange{}
+	seen := make(map[string]bool, len(newDependencies))
+	for _, dependency := range newDependencies {
+		key := getModrinthDependencyKey(dependency)
+		seen[key] = true
+
+		old, found := oldMap[key]
+		switch {
+		case !found:
+			changes = append(changes, toModUpdateDependencyChange(dependency, ModDependencyAdded))
+		case old.DependencyType != dependency.DependencyType:
+			changes = append(changes, toModUpdateDependencyChange(dependency, ModDependencyChanged))
+		}
+	}
+
+	for _, dependency := range oldDependencies {
+		if !seen[getModrinthDependencyKey(dependency)] {
+			changes = append(changes, toModUpdateDependencyChange(dependency, ModDependencyRemoved))
+		}
+	}
+
+	return changes
+}
+
+func CheckModUpdates(gameDir, versionId, minecraftDir string, options ModUpdateOptions) (*ModUpdatePlan, error) {
+	loader, err := GetVersionLoader(versionId, minecraftDir)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ModUpdatePlan{
+		VersionId:        versionId,
+		MinecraftVersion: loader.MinecraftVersion,
+		Loader:           loader.Loader,
+		Updates:          []ModUpdate{},
+		Unknown:          []string{},
+	}
+	if loader.Loader == LoaderVanilla {
+		return plan, nil
+	}
+
+	modsDir := filepath.Join(gameDir, "mods")
+	entries, err := os.ReadDir(modsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return plan, nil
+		}
+		return nil, err
+	}
+
+	fileHashes := make(map[string]string)
+	var hashes []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".jar") {
+			continue
+		}
+
+		hash, err := getSHA512Hash(filepath.Join(modsDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		fileHashes[hash] = entry.Name()
+		hashes = append(hashes, hash)
+	}
+
+	current, err := GetModrinthVersionsFromHashes(options.APIURL, hashes, "sha512")
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up installed mods: %w", err)
+	}
+
+	loaders := GetCompatibleModLoaders(loader.Loader)
+
+	var known []string
+	for _, hash := range hashes {
+		if _, found := current[hash]; found {
+			known = append(known, hash)
+		} else {
+			plan.Unknown = append(plan.Unknown, fileHashes[hash])
+		}
+	}
+
+	updates, err := getModrinthUpdates(options.APIURL, known, "sha512", loaders, []string{loader.MinecraftVersion})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for mod updates: %w", err)
+	}
+
+	siteURL := options.SiteURL
+	if siteURL == "" && options.APIURL == "" {
+		siteURL = _modrinthSiteURL
+	}
+
+	for _, hash := range known {
+		oldVersion := current[hash]
+		newVersion, found := updates[hash]
+		if !found || newVersion.Id == oldVersion.Id {
+			continue
+		}
+
+		newFile, found := getModrinthPrimaryFile(newVersion)
+		if !found || newFile.Hashes["sha512"] == hash {
+			continue
+		}
+
+		update := ModUpdate{
+			FileName:          fileHashes[hash],
+			ProjectId:         newVersion.ProjectId,
+			OldVersionId:      oldVersion.Id,
+			OldVersion:        oldVersion.VersionNumber,
+			NewVersionId:      newVersion.Id,
+			NewVersion:        newVersion.VersionNumber,
+			NewFile:           newFile,
+			Changelog:         newVersion.Changelog,
+			DependencyChanges: getModUpdateDependencyChanges(oldVersion.Dependencies, newVersion.Dependencies),
+		}
+		if siteURL != "" {
+			update.ChangelogURL = fmt.Sprintf("%s/project/%s/version/%s", strings.TrimSuffix(siteURL, "/"), newVersion.ProjectId, newVersion.Id)
+		}
+
+		plan.Updates = append(plan.Updates, update)
+	}
+
+	sort.Slice(plan.Updates, func(i, j int) bool {
+		return strings.ToLower(plan.Updates[i].FileName) < strings.ToLower(plan.Updates[j].FileName)
+	})
+	sort.Strings(plan.Unknown)
+
+	return plan, nil
+}
+
+// ApplyModUpdates downloads every new file before touching mods/, then swaps them in and rolls back on failure.
+// commit runs once the new files are in place, to record them elsewhere; if it fails the old files are restored.
+func ApplyModUpdates(gameDir string, updates []ModUpdate, commit func() error, callback *Callback) error {
+	callback = getCallback(callback)
+
+	modsDir := filepath.Join(gameDir, "mods")
+	stagingDir := filepath.Join(modsDir, modUpdateStagingDirectory)
+	backupDir := filepath.Join(modsDir, modUpdateBackupDirectory)
+
+	replaced := make(map[string]bool, len(updates))
+	for _, update := range updates {
+		replaced[update.FileName] = true
+	}
+
+	var files []MrpackFile
+	for _, update := range updates {
+		if !isValidContentName(update.FileName) || !isValidContentName(update.NewFile.Filename) {
+			return fmt.Errorf("invalid file name in update for %s", update.FileName)
+		}
+		if !fileExists(filepath.Join(modsDir, update.FileName)) {
+			return fmt.Errorf("mod not found: %s", update.FileName)
+		}
+		if !replaced[update.NewFile.Filename] && fileExists(filepath.Join(modsDir, update.NewFile.Filename)) {
+			return fmt.Errorf("%w: %s", ErrorModUpdateConflict, update.NewFile.Filename)
+		}
+
+		files = append(files, MrpackFile{
+			Path:      update.NewFile.Filename,
+			Hashes:    update.NewFile.Hashes,
+			Downloads: []string{update.NewFile.Url},
+		})
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	os.RemoveAll(stagingDir)
+	defer os.RemoveAll(stagingDir)
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return err
+	}
+
+	if err := installMrpackFiles(files, stagingDir, *callback); err != nil {
+		return err
+	}
+
+	os.RemoveAll(backupDir)
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return err
+	}
+
+	callback.Status("Replacing Mods...")
+	var backedUp, placed []string
+	rollback := func() {
+		for _, fileName := range placed {
+			os.Remove(filepath.Join(modsDir, fileName))
+		}
+		for _, fileName := range backedUp {
+			os.Rename(filepath.Join(backupDir, fileName), filepath.Join(modsDir, fileName))
+		}
+		os.RemoveAll(backupDir)
+	}
+
+	for _, update := range updates {
+		if err := os.Rename(filepath.Join(modsDir, update.FileName), filepath.Join(backupDir, update.FileName)); err != nil {
+			rollback()
+			return err
+		}
+		backedUp = append(backedUp, update.FileName)
+	}
+
+	for _, update := range updates {
+		if err := os.Rename(filepath.Join(stagingDir, update.NewFile.Filename), filepath.Join(modsDir, update.NewFile.Filename)); err != nil {
+			rollback()
+			return err
+		}
+		placed = append(placed, update.NewFile.Filename)
+	}
+
+	if commit != nil {
+		if err := commit(); err != nil {
+			rollback()
+			return err
+		}
+	}
+
+	os.RemoveAll(backupDir)
+	callback.Status(fmt.Sprintf("Updated %d mods.", len(updates)))
+	return nil
+}
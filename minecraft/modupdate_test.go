@@ -0,0 +1,340 @@
+package minecraft
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func testSHA512(data string) string {
+	sum := sha512.Sum512([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeTestFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTestVersion installs a version JSON under minecraftDir/versions, as the loader installers would.
+func writeTestVersion(t *testing.T, minecraftDir, id string, data ClientJson) {
+	t.Helper()
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(minecraftDir, "versions", id, id+".json"), string(encoded))
+}
+
+func testModrinthVersion(id, projectId, versionNumber, fileName, content string, dependencies ...ModrinthVersionDependency) ModrinthVersion {
+	return ModrinthVersion{
+		Id:            id,
+		ProjectId:     projectId,
+		VersionNumber: versionNumber,
+		Changelog:     "Changes in " + versionNumber,
+		Files: []ModrinthVersionFile{{
+			Filename: fileName,
+			Primary:  true,
+			Hashes:   map[string]string{"sha512": testSHA512(content)},
+		}},
+		Dependencies: dependencies,
+	}
+}
+
+func TestCheckModUpdates(t *testing.T) {
+	minecraftDir := t.TempDir()
+	gameDir := t.TempDir()
+	modsDir := filepath.Join(gameDir, "mods")
+
+	writeTestVersion(t, minecraftDir, "1.21.1", ClientJson{})
+	writeTestVersion(t, minecraftDir, "fabric-loader-0.16.9-1.21.1", ClientJson{
+		InheritsFrom: "1.21.1",
+		Libraries:    []ClientJsonLibrary{{Name: "net.fabricmc:fabric-loader:0.16.9"}},
+	})
+
+	writeTestFile(t, filepath.Join(modsDir, "sodium-0.5.jar"), "sodium 0.5")
+	writeTestFile(t, filepath.Join(modsDir, "lithium-0.12.jar"), "lithium 0.12")
+	writeTestFile(t, filepath.Join(modsDir, "Custom.jar"), "not on modrinth")
+	writeTestFile(t, filepath.Join(modsDir, "notes.txt"), "not a mod")
+
+	fabricApi := "P7dR8mSH"
+	indium := "Orvt0mRa"
+	sodium := testSHA512("sodium 0.5")
+	lithium := testSHA512("lithium 0.12")
+	current := map[string]ModrinthVersion{
+		sodium:  testModrinthVersion("sodium-05", "AANobbMI", "0.5", "sodium-0.5.jar", "sodium 0.5", ModrinthVersionDependency{ProjectId: &indium, DependencyType: "optional"}),
+		lithium: testModrinthVersion("lithium-012", "gvQqBUqZ", "0.12", "lithium-0.12.jar", "lithium 0.12"),
+	}
+	latest := map[string]ModrinthVersion{
+		sodium:  testModrinthVersion("sodium-06", "AANobbMI", "0.6", "sodium-0.6.jar", "sodium 0.6", ModrinthVersionDependency{ProjectId: &fabricApi, DependencyType: "required"}),
+		lithium: current[lithium],
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Hashes       []string `json:"hashes"`
+			Algorithm    string   `json:"algorithm"`
+			Loaders      []string `json:"loaders"`
+			GameVersions []string `json:"game_versions"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.Method != http.MethodPost || body.Algorithm != "sha512" {
+			t.Errorf("%s %s with algorithm %q, want a sha512 POST", r.Method, r.URL.Path, body.Algorithm)
+		}
+
+		versions := current
+		switch r.URL.Path {
+		case "/version_files":
+		case "/version_files/update":
+			if !slices.Contains(body.Loaders, LoaderFabric) || !reflect.DeepEqual(body.GameVersions, []string{"1.21.1"}) {
+				t.Errorf("update check for loaders %v and game versions %v, want fabric on 1.21.1", body.Loaders, body.GameVersions)
+			}
+			versions = latest
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		result := map[string]ModrinthVersion{}
+		for _, hash := range body.Hashes {
+			if version, found := versions[hash]; found {
+				result[hash] = version
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+
+	plan, err := CheckModUpdates(gameDir, "fabric-loader-0.16.9-1.21.1", minecraftDir, ModUpdateOptions{
+		APIURL:  server.URL,
+		SiteURL: "https://modrinth.example.invalid/",
+	})
+	if err != nil {
+		t.Fatalf("CheckModUpdates: %v", err)
+	}
+
+	want := &ModUpdatePlan{
+		VersionId:        "fabric-loader-0.16.9-1.21.1",
+		MinecraftVersion: "1.21.1",
+		Loader:           LoaderFabric,
+		Updates: []ModUpdate{{
+			FileName:     "sodium-0.5.jar",
+			ProjectId:    "AANobbMI",
+			OldVersionId: "sodium-05",
+			OldVersion:   "0.5",
+			NewVersionId: "sodium-06",
+			NewVersion:   "0.6",
+			NewFile:      latest[sodium].Files[0],
+			Changelog:    "Changes in 0.6",
+			ChangelogURL: "https://modrinth.example.invalid/project/AANobbMI/version/sodium-06",
+			DependencyChanges: []ModUpdateDependencyChange{
+				{ProjectId: fabricApi, DependencyType: "required", Change: ModDependencyAdded},
+				{ProjectId: indium, DependencyType: "optional", Change: ModDependencyRemoved},
+			},
+		}},
+		Unknown: []string{"Custom.jar"},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("CheckModUpdates =\n%+v\nwant\n%+v", plan, want)
+	}
+}
+
+func TestCheckModUpdatesVanilla(t *testing.T) {
+	minecraftDir := t.TempDir()
+	gameDir := t.TempDir()
+	writeTestVersion(t, minecraftDir, "1.21.1", ClientJson{})
+	writeTestFile(t, filepath.Join(gameDir, "mods", "sodium.jar"), "sodium")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s for a vanilla version", r.URL.Path)
+	}))
+	defer server.Close()
+
+	plan, err := CheckModUpdates(gameDir, "1.21.1", minecraftDir, ModUpdateOptions{APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("CheckModUpdates: %v", err)
+	}
+	if plan.Loader != LoaderVanilla || len(plan.Updates) != 0 || len(plan.Unknown) != 0 {
+		t.Errorf("CheckModUpdates = %+v, want an empty vanilla plan", plan)
+	}
+}
+
+func TestApplyModUpdates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sodium-0.6.jar":
+			w.Write([]byte("sodium 0.6"))
+		case "/lithium-0.13.jar":
+			w.Write([]byte("lithium 0.13"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	update := func(oldName, newName, content string) ModUpdate {
+		return ModUpdate{
+			FileName:     oldName,
+			NewVersionId: newName,
+			NewFile: ModrinthVersionFile{
+				Filename: newName,
+				Url:      server.URL + "/" + newName,
+				Hashes:   map[string]string{"sha512": testSHA512(content)},
+			},
+		}
+	}
+	sodium := update("sodium-0.5.jar", "sodium-0.6.jar", "sodium 0.6")
+	lithium := update("lithium-0.12.jar", "lithium-0.13.jar", "lithium 0.13")
+	missing := update("lithium-0.12.jar", "lithium-0.14.jar", "lithium 0.14")
+	corrupt := update("lithium-0.12.jar", "lithium-0.13.jar", "something else")
+
+	original := map[string]string{
+		"sodium-0.5.jar":   "sodium 0.5",
+		"lithium-0.12.jar": "lithium 0.12",
+	}
+	updated := map[string]string{
+		"sodium-0.6.jar":   "sodium 0.6",
+		"lithium-0.13.jar": "lithium 0.13",
+	}
+
+	tests := []struct {
+		name       string
+		updates    []ModUpdate
+		setup      func(t *testing.T, modsDir string)
+		commitErr  error
+		wantErr    bool
+		wantCommit bool
+		wantFiles  map[string]string
+	}{
+		{
+			name:       "swaps every file",
+			updates:    []ModUpdate{sodium, lithium},
+			wantCommit: true,
+			wantFiles:  updated,
+		},
+		{
+			name:      "failed download",
+			updates:   []ModUpdate{sodium, missing},
+			wantErr:   true,
+			wantFiles: original,
+		},
+		{
+			name:      "checksum mismatch",
+			updates:   []ModUpdate{sodium, corrupt},
+			wantErr:   true,
+			wantFiles: original,
+		},
+		{
+			name:    "failed rename",
+			updates: []ModUpdate{sodium, lithium},
+			// A directory in the way is not an existing file, so only the final rename fails.
+			setup: func(t *testing.T, modsDir string) {
+				writeTestFile(t, filepath.Join(modsDir, "lithium-0.13.jar", "keep"), "")
+			},
+			wantErr:   true,
+			wantFiles: original,
+		},
+		{
+			name:       "failed index write",
+			updates:    []ModUpdate{sodium, lithium},
+			commitErr:  errors.New("disk full"),
+			wantErr:    true,
+			wantCommit: true,
+			wantFiles:  original,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gameDir := t.TempDir()
+			modsDir := filepath.Join(gameDir, "mods")
+			for name, content := range original {
+				writeTestFile(t, filepath.Join(modsDir, name), content)
+			}
+			indexPath := filepath.Join(modsDir, ".index.json")
+			writeTestFile(t, indexPath, "original index")
+			if test.setup != nil {
+				test.setup(t, modsDir)
+			}
+
+			committed := false
+			commit := func() error {
+				committed = true
+				for name, content := range updated {
+					if data, err := os.ReadFile(filepath.Join(modsDir, name)); err != nil || string(data) != content {
+						t.Errorf("%s is not in place when the index is written", name)
+					}
+				}
+				if test.commitErr != nil {
+					return test.commitErr
+				}
+				return os.WriteFile(indexPath, []byte("updated index"), 0644)
+			}
+
+			err := ApplyModUpdates(gameDir, test.updates, commit, nil)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ApplyModUpdates error = %v, want error %v", err, test.wantErr)
+			}
+			if committed != test.wantCommit {
+				t.Errorf("commit called = %v, want %v", committed, test.wantCommit)
+			}
+
+			for name, content := range test.wantFiles {
+				if data, err := os.ReadFile(filepath.Join(modsDir, name)); err != nil || string(data) != content {
+					t.Errorf("%s = %q, %v; want %q", name, data, err, content)
+				}
+			}
+			for _, name := range []string{"sodium-0.5.jar", "sodium-0.6.jar", "lithium-0.12.jar"} {
+				if _, wanted := test.wantFiles[name]; !wanted && fileExists(filepath.Join(modsDir, name)) {
+					t.Errorf("%s was left in mods/", name)
+				}
+			}
+
+			wantIndex := "original index"
+			if !test.wantErr {
+				wantIndex = "updated index"
+			}
+			if data, _ := os.ReadFile(indexPath); string(data) != wantIndex {
+				t.Errorf("index = %q, want %q", data, wantIndex)
+			}
+			for _, dir := range []string{modUpdateStagingDirectory, modUpdateBackupDirectory} {
+				if _, err := os.Stat(filepath.Join(modsDir, dir)); !os.IsNotExist(err) {
+					t.Errorf("%s was left behind", dir)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyModUpdatesConflict(t *testing.T) {
+	gameDir := t.TempDir()
+	modsDir := filepath.Join(gameDir, "mods")
+	writeTestFile(t, filepath.Join(modsDir, "sodium-0.5.jar"), "sodium 0.5")
+	writeTestFile(t, filepath.Join(modsDir, "sodium-0.6.jar"), "installed by hand")
+
+	updates := []ModUpdate{{
+		FileName: "sodium-0.5.jar",
+		NewFile:  ModrinthVersionFile{Filename: "sodium-0.6.jar", Url: "http://127.0.0.1:0/sodium-0.6.jar"},
+	}}
+	if err := ApplyModUpdates(gameDir, updates, nil, nil); !errors.Is(err, ErrorModUpdateConflict) {
+		t.Fatalf("ApplyModUpdates = %v, want ErrorModUpdateConflict", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(modsDir, "sodium-0.6.jar")); string(data) != "installed by hand" {
+		t.Error("the conflicting file was overwritten")
+	}
+}
@@ -0,0 +1,475 @@
+package minecraft
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrorInvalidMrpack error = errors.New("invalid mrpack file")
+var ErrorMrpackDirectoryRequired error = errors.New("a modpack directory is required")
+
+func readMrpackIndex(r *zip.Reader) (MrpackIndex, error) {
+	var index MrpackIndex
+
+	f, err := r.Open("modrinth.index.json")
+	if err != nil {
+		return index, fmt.Errorf("%w: modrinth.index.json not found", ErrorInvalidMrpack)
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&index); err != nil {
+		return index, fmt.Errorf("%w: %v", ErrorInvalidMrpack, err)
+	}
+	if index.Game != "minecraft" || index.Dependencies["minecraft"] == "" {
+		return index, fmt.Errorf("%w: not a minecraft modpack", ErrorInvalidMrpack)
+	}
+
+	return index, nil
+}
+
+func getMrpackLoader(dependencies map[string]string) (string, string) {
+	switch {
+	case dependencies["fabric-loader"] != "":
+		return LoaderFabric, dependencies["fabric-loader"]
+	case dependencies["quilt-loader"] != "":
+		return LoaderQuilt, dependencies["quilt-loader"]
+	case dependencies["forge"] != "":
+		return LoaderForge, dependencies["forge"]
+	case dependencies["neoforge"] != "":
+		return LoaderNeoForge, dependencies["neoforge"]
+	default:
+		return LoaderVanilla, ""
+	}
+}
+
+func getLoaderVersionId(loader, minecraftVersion, loaderVersion string) string {
+	switch loader {
+	case LoaderFabric:
+		return fmt.Sprintf("fabric-loader-%s-%s", loaderVersion, minecraftVersion)
+	case LoaderQuilt:
+		return fmt.Sprintf("quilt-loader-%s-%s", loaderVersion, minecraftVersion)
+	case LoaderForge, LoaderNeoForge:
+		_, versionId := getForgeInstallerURL(loader, minecraftVersion, loaderVersion)
+		return versionId
+	default:
+		return minecraftVersion
+	}
+}
+
+func isMrpackFileEnabled(file MrpackFile, optionalFiles []string) bool {
+	if file.Env == nil {
+		return true
+	}
+
+	switch file.Env.Client {
+	case "unsupported":
+		return false
+	case "optional":
+		return slices.Contains(optionalFiles, file.Path)
+	default:
+		return true
+	}
+}
+
+func GetMrpackInformation(path string) (MrpackInformation, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return MrpackInformation{}, err
+	}
+	defer r.Close()
+
+	index, err := readMrpackIndex(&r.Reader)
+	if err != nil {
+		return MrpackInformation{}, err
+	}
+
+	loader, loaderVersion := getMrpackLoader(index.Dependencies)
+	info := MrpackInformation{
+		Name:             index.Name,
+		Summary:          index.Summary,
+		VersionId:        index.VersionId,
+		FormatVersion:    index.FormatVersion,
+		MinecraftVersion: index.Dependencies["minecraft"],
+		Loader:           loader,
+		LoaderVersion:    loaderVersion,
+		OptionalFiles:    []string{},
+	}
+
+	for _, file := range index.Files {
+		if file.Env != nil && file.Env.Client == "optional" {
+			info.OptionalFiles = append(info.OptionalFiles, file.Path)
+		}
+	}
+
+	return info, nil
+}
+
+func GetMrpackLaunchVersion(path string) (string, error) {
+	info, err := GetMrpackInformation(path)
+	if err != nil {
+		return "", err
+	}
+
+	return getLoaderVersionId(info.Loader, info.MinecraftVersion, info.LoaderVersion), nil
+}
+
+func downloadMrpackFile(file MrpackFile, modpackDir string) error {
+	fullPath := filepath.Join(modpackDir, filepath.FromSlash(file.Path))
+	if !strings.HasPrefix(fullPath, filepath.Clean(modpackDir)+string(os.PathSeparator)) {
+		return fmt.Errorf("invalid file path: %s", file.Path)
+	}
+
+	if len(file.Downloads) == 0 {
+		return fmt.Errorf("no download url for %s", file.Path)
+	}
+
+	var lastErr error
+	for _, url := range file.Downloads {
+		if err := downloadFile(url, fullPath, modpackDir, file.Hashes["sha1"], false); err != nil {
+			lastErr = err
+			continue
+		}
+
+		if sha512Hash := file.Hashes["sha512"]; sha512Hash != "" {
+			computed, err := getSHA512Hash(fullPath)
+			if err != nil {
+				return err
+			}
+			if computed != sha512Hash {
+				os.Remove(fullPath)
+				lastErr = fmt.Errorf("invalid checksum: expected %s, got %s", sha512Hash, computed)
+				continue
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("error downloading %s: %w", file.Path, lastErr)
+}
+
+func installMrpackFiles(files []MrpackFile, modpackDir string, callback Callback) error {
+	var wg sync.WaitGroup
+	var progressWG sync.WaitGroup
+	var errMu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, maxWorkers)
+	progressCh := make(chan int, len(files))
+
+	callback.Status("Downloading Modpack Files...")
+	callback.Progress("0")
+	callback.Max(strconv.Itoa(len(files)))
+
+	progressWG.Add(1)
+	go func() {
+		defer progressWG.Done()
+		completed := 0
+		for progress := range progressCh {
+			completed += progress
+			callback.Progress(strconv.Itoa(completed))
+		}
+	}()
+
+	for _, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(file MrpackFile) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := downloadMrpackFile(file, modpackDir); err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				errMu.Unlock()
+			}
+			progressCh <- 1
+		}(file)
+	}
+
+	wg.Wait()
+	close(progressCh)
+	progressWG.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	callback.Status("Modpack Files download complete.")
+	return nil
+}
+
+func InstallMrpack(path, minecraftDir, modpackDir string, options MrpackInstallOptions, callback *Callback) (string, error) {
+	callback = getCallback(callback)
+
+	// Overrides would overwrite the shared game directory's mods and configs, so there is no default.
+	if modpackDir == "" {
+		return "", ErrorMrpackDirectoryRequired
+	}
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	index, err := readMrpackIndex(&r.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(modpackDir, 0755); err != nil {
+		return "", err
+	}
+
+	var files []MrpackFile
+	for _, file := range index.Files {
+		if isMrpackFileEnabled(file, options.OptionalFiles) {
+			files = append(files, file)
+		}
+	}
+
+	if err := installMrpackFiles(files, modpackDir, *callback); err != nil {
+		return "", err
+	}
+
+	callback.Status("Extracting Overrides...")
+	for _, prefix := range []string{"overrides/", "client-overrides/"} {
+		if _, err := extractZipDirectory(&r.Reader, prefix, modpackDir); err != nil {
+			return "", fmt.Errorf("error extracting %s: %w", strings.TrimSuffix(prefix, "/"), err)
+		}
+	}
+
+	minecraftVersion := index.Dependencies["minecraft"]
+	loader, loaderVersion := getMrpackLoader(index.Dependencies)
+	launchVersion := getLoaderVersionId(loader, minecraftVersion, loaderVersion)
+
+	if options.SkipDependenciesInstall {
+		return launchVersion, nil
+	}
+
+	installedVersion, err := InstallLoader(loader, minecraftVersion, loaderVersion, MinecraftOptions{InstallDirectory: minecraftDir}, callback)
+	if err != nil {
+		return "", fmt.Errorf("failed to install %s %s: %w", loader, minecraftVersion, err)
+	}
+
+	return installedVersion, nil
+}
+
+var DefaultMrpackExportExclude = []string{
+	"versions",
+	"libraries",
+	"assets",
+	"runtime",
+	"natives",
+	"logs",
+	"crash-reports",
+	"screenshots",
+	"saves",
+	".fabric",
+	".mixin.out",
+	"launcher_profiles.json",
+	"usercache.json",
+	"usernamecache.json",
+}
+
+var mrpackLookupDirectories = []string{"mods", "resourcepacks", "shaderpacks"}
+
+func isMrpackExportExcluded(relPath string, exclude []string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, pattern := range exclude {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		if relPath == pattern || strings.HasPrefix(relPath, pattern+"/") {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, relPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func isMrpackLookupCandidate(relPath string) bool {
+	dir, name := filepath.Split(filepath.ToSlash(relPath))
+	if !slices.Contains(mrpackLookupDirectories, strings.TrimSuffix(dir, "/")) {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".jar" || ext == ".zip"
+}
+
+func getMrpackDependencies(versionId, minecraftDir string) (map[string]string, error) {
+	info, err := GetVersionLoader(versionId, minecraftDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies := map[string]string{
+		"minecraft": info.MinecraftVersion,
+	}
+	switch info.Loader {
+	case LoaderFabric:
+		dependencies["fabric-loader"] = info.LoaderVersion
+	case LoaderQuilt:
+		dependencies["quilt-loader"] = info.LoaderVersion
+	case LoaderForge:
+		dependencies["forge"] = info.LoaderVersion
+	case LoaderNeoForge:
+		dependencies["neoforge"] = info.LoaderVersion
+	}
+	return dependencies, nil
+}
+
+func ExportMrpack(gameDir, versionId string, options MrpackExportOptions) error {
+	if options.OutputPath == "" {
+		return errors.New("output path is required")
+	}
+	if options.MinecraftDirectory == "" {
+		options.MinecraftDirectory = gameDir
+	}
+	if options.Exclude == nil {
+		options.Exclude = DefaultMrpackExportExclude
+	}
+	if options.Lookup == nil {
+		options.Lookup = NewModrinthFileLookup("")
+	}
+	if options.Name == "" {
+		options.Name = filepath.Base(gameDir)
+	}
+	if options.VersionId == "" {
+		options.VersionId = "1.0.0"
+	}
+
+	dependencies, err := getMrpackDependencies(versionId, options.MinecraftDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to resolve version %s: %w", versionId, err)
+	}
+
+	outputPath, err := filepath.Abs(options.OutputPath)
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	err = filepath.WalkDir(gameDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(gameDir, path)
+		if err != nil || relPath == "." {
+			return err
+		}
+		if isMrpackExportExcluded(relPath, options.Exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if absPath, _ := filepath.Abs(path); absPath == outputPath {
+			return nil
+		}
+
+		files = append(files, relPath)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	candidates := make(map[string]MrpackFile)
+	var sha1Hashes []string
+	for _, relPath := range files {
+		if !isMrpackLookupCandidate(relPath) {
+			continue
+		}
+
+		fullPath := filepath.Join(gameDir, relPath)
+		sha1Hash, err := getSHA1Hash(fullPath)
+		if err != nil {
+			return err
+		}
+		sha512Hash, err := getSHA512Hash(fullPath)
+		if err != nil {
+			return err
+		}
+		stat, err := os.Stat(fullPath)
+		if err != nil {
+			return err
+		}
+
+		candidates[relPath] = MrpackFile{
+			Path:     filepath.ToSlash(relPath),
+			Hashes:   map[string]string{"sha1": sha1Hash, "sha512": sha512Hash},
+			FileSize: stat.Size(),
+		}
+		sha1Hashes = append(sha1Hashes, sha1Hash)
+	}
+
+	urls, err := options.Lookup(sha1Hashes)
+	if err != nil {
+		return fmt.Errorf("failed to look up files: %w", err)
+	}
+
+	index := MrpackIndex{
+		FormatVersion: 1,
+		Game:          "minecraft",
+		VersionId:     options.VersionId,
+		Name:          options.Name,
+		Summary:       options.Summary,
+		Files:         []MrpackFile{},
+		Dependencies:  dependencies,
+	}
+
+	var overrides []string
+	for _, relPath := range files {
+		file, isCandidate := candidates[relPath]
+		if url, found := urls[file.Hashes["sha1"]]; isCandidate && found {
+			file.Downloads = []string{url}
+			index.Files = append(index.Files, file)
+			continue
+		}
+		overrides = append(overrides, relPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := zip.NewWriter(out)
+
+	indexWriter, err := w.Create("modrinth.index.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(indexWriter)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(index); err != nil {
+		return err
+	}
+
+	for _, relPath := range overrides {
+		if err := addFileToZip(w, filepath.Join(gameDir, relPath), "overrides/"+filepath.ToSlash(relPath)); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
@@ -0,0 +1,247 @@
+package minecraft
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrorInvalidMultiMCInstance error = errors.New("invalid multimc instance")
+
+var multiMCComponentLoaders = map[string]string{
+	"net.fabricmc.fabric-loader": LoaderFabric,
+	"org.quiltmc.quilt-loader":   LoaderQuilt,
+	"net.minecraftforge":         LoaderForge,
+	"net.neoforged":              LoaderNeoForge,
+	"net.neoforged.neoforge":     LoaderNeoForge,
+}
+
+func readINI(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' || line[0] == '[' {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			} else {
+				value = value[1 : len(value)-1]
+			}
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// getINIOverride returns a setting only when its override flag is on. MultiMC instances that predate the flag always override.
+func getINIOverride(cfg map[string]string, overrideKey, key string) string {
+	if override, found := cfg[overrideKey]; found && override != "true" {
+		return ""
+	}
+	return cfg[key]
+}
+
+func ReadMultiMCInstance(dir string) (MultiMCInstance, error) {
+	instance := MultiMCInstance{Path: dir, Loader: LoaderVanilla}
+
+	cfg, err := readINI(filepath.Join(dir, "instance.cfg"))
+	if err != nil {
+		return instance, fmt.Errorf("%w: %v", ErrorInvalidMultiMCInstance, err)
+	}
+
+	pack, err := readJSON[MultiMCPack](filepath.Join(dir, "mmc-pack.json"))
+	if err != nil {
+		return instance, fmt.Errorf("%w: %v", ErrorInvalidMultiMCInstance, err)
+	}
+
+	for _, component := range pack.Components {
+		version := component.Version
+		if version == "" {
+			version = component.CachedVersion
+		}
+
+		if component.Uid == "net.minecraft" {
+			instance.MinecraftVersion = version
+		} else if loader, found := multiMCComponentLoaders[component.Uid]; found {
+			instance.Loader = loader
+			instance.LoaderVersion = version
+		}
+	}
+	if instance.MinecraftVersion == "" {
+		return instance, fmt.Errorf("%w: no net.minecraft component", ErrorInvalidMultiMCInstance)
+	}
+
+	instance.Name = cfg["name"]
+	if instance.Name == "" {
+		instance.Name = filepath.Base(dir)
+	}
+
+	for _, name := range []string{".minecraft", "minecraft"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.IsDir() {
+			instance.GameDirectory = filepath.Join(dir, name)
+			break
+		}
+	}
+
+	instance.MaxMemory, _ = strconv.Atoi(getINIOverride(cfg, "OverrideMemory", "MaxMemAlloc"))
+	instance.JVMArguments = getINIOverride(cfg, "OverrideJavaArgs", "JvmArgs")
+	instance.JavaPath = getINIOverride(cfg, "OverrideJavaLocation", "JavaPath")
+	instance.WindowWidth, _ = strconv.Atoi(getINIOverride(cfg, "OverrideWindow", "MinecraftWinWidth"))
+	instance.WindowHeight, _ = strconv.Atoi(getINIOverride(cfg, "OverrideWindow", "MinecraftWinHeight"))
+
+	return instance, nil
+}
+
+func getMultiMCDataDirectories() map[string][]string {
+	homeDir, _ := os.UserHomeDir()
+
+	switch runtime.GOOS {
+	case "windows":
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			appData = filepath.Join(homeDir, "AppData", "Roaming")
+		}
+		return map[string][]string{
+			"PrismLauncher": {filepath.Join(appData, "PrismLauncher")},
+			"PolyMC":        {filepath.Join(appData, "PolyMC")},
+		}
+	case "darwin":
+		support := filepath.Join(homeDir, "Library", "Application Support")
+		return map[string][]string{
+			"PrismLauncher": {filepath.Join(support, "PrismLauncher")},
+			"PolyMC":        {filepath.Join(support, "PolyMC")},
+			"MultiMC":       {filepath.Join(support, "MultiMC")},
+		}
+	default:
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			dataHome = filepath.Join(homeDir, ".local", "share")
+		}
+		return map[string][]string{
+			"PrismLauncher": {
+				filepath.Join(dataHome, "PrismLauncher"),
+				filepath.Join(homeDir, ".var", "app", "org.prismlauncher.PrismLauncher", "data", "PrismLauncher"),
+			},
+			"PolyMC": {
+				filepath.Join(dataHome, "PolyMC"),
+				filepath.Join(homeDir, ".var", "app", "org.polymc.PolyMC", "data", "PolyMC"),
+			},
+			"MultiMC": {filepath.Join(dataHome, "multimc")},
+		}
+	}
+}
+
+func getMultiMCInstancesDirectory(dataDir string) string {
+	for _, cfgName := range []string{"prismlauncher.cfg", "polymc.cfg", "multimc.cfg"} {
+		cfg, err := readINI(filepath.Join(dataDir, cfgName))
+		if err != nil {
+			continue
+		}
+		if dir := cfg["InstanceDir"]; dir != "" {
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(dataDir, dir)
+			}
+			return dir
+		}
+	}
+	return filepath.Join(dataDir, "instances")
+}
+
+// FindMultiMCInstances discovers instances in the default Prism Launcher, PolyMC and MultiMC data directories.
+func FindMultiMCInstances() ([]MultiMCInstance, error) {
+	instances := []MultiMCInstance{}
+	seen := make(map[string]bool)
+
+	for launcher, dataDirs := range getMultiMCDataDirectories() {
+		for _, dataDir := range dataDirs {
+			instancesDir := getMultiMCInstancesDirectory(dataDir)
+			if seen[instancesDir] {
+				continue
+			}
+			seen[instancesDir] = true
+
+			entries, err := os.ReadDir(instancesDir)
+			if err != nil {
+				continue
+			}
+
+			for _, entry := range entries {
+				if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || strings.HasPrefix(entry.Name(), "_") {
+					continue
+				}
+
+				instance, err := ReadMultiMCInstance(filepath.Join(instancesDir, entry.Name()))
+				if err != nil {
+					continue
+				}
+				instance.Launcher = launcher
+				instances = append(instances, instance)
+			}
+		}
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		return strings.ToLower(instances[i].Name) < strings.ToLower(instances[j].Name)
+	})
+
+	return instances, nil
+}
+
+// CopyGameDirectory copies src into dst, or symlinks dst to src when link is set.
+func CopyGameDirectory(src, dst string, link bool) error {
+	if link {
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.Symlink(src, dst)
+	}
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type().IsRegular():
+			return copyFile(path, target)
+		default:
+			return nil
+		}
+	})
+}
@@ -0,0 +1,363 @@
+package minecraft
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// _prismInstanceCfg follows an instance.cfg written by Prism Launcher 8, with the [General] section and override flags.
+const _prismInstanceCfg = `[General]
+ConfigVersion=1.2
+InstanceType=OneSix
+iconKey=default
+name=Fabulously Optimized
+JavaPath=/usr/lib/jvm/java-21-openjdk/bin/java
+OverrideJavaLocation=true
+JvmArgs=-XX:+UseG1GC -Dfile.encoding=UTF-8
+OverrideJavaArgs=true
+MaxMemAlloc=6144
+MinMemAlloc=512
+OverrideMemory=true
+MinecraftWinWidth=1280
+MinecraftWinHeight=720
+OverrideWindow=false
+notes="First line\nSecond line"
+`
+
+// _prismPackJson follows the mmc-pack.json Prism Launcher writes for a Fabric instance.
+const _prismPackJson = `{
+    "components": [
+        {"cachedName": "LWJGL 3", "cachedVersion": "3.3.3", "dependencyOnly": true, "uid": "org.lwjgl3", "version": "3.3.3"},
+        {"cachedName": "Minecraft", "cachedVersion": "1.21.1", "important": true, "uid": "net.minecraft", "version": "1.21.1"},
+        {"cachedName": "Intermediary Mappings", "cachedVersion": "1.21.1", "dependencyOnly": true, "uid": "net.fabricmc.intermediary", "version": "1.21.1"},
+        {"cachedName": "Fabric Loader", "cachedVersion": "0.16.9", "uid": "net.fabricmc.fabric-loader", "version": "0.16.9"}
+    ],
+    "formatVersion": 1
+}
+`
+
+func TestReadINI(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[string]string
+	}{
+		{
+			name: "prism instance",
+			data: _prismInstanceCfg,
+			want: map[string]string{
+				"ConfigVersion":        "1.2",
+				"InstanceType":         "OneSix",
+				"iconKey":              "default",
+				"name":                 "Fabulously Optimized",
+				"JavaPath":             "/usr/lib/jvm/java-21-openjdk/bin/java",
+				"OverrideJavaLocation": "true",
+				"JvmArgs":              "-XX:+UseG1GC -Dfile.encoding=UTF-8",
+				"OverrideJavaArgs":     "true",
+				"MaxMemAlloc":          "6144",
+				"MinMemAlloc":          "512",
+				"OverrideMemory":       "true",
+				"MinecraftWinWidth":    "1280",
+				"MinecraftWinHeight":   "720",
+				"OverrideWindow":       "false",
+				"notes":                "First line\nSecond line",
+			},
+		},
+		{
+			name: "comments, blank lines and spacing",
+			data: "# comment\r\n; another\r\n\r\n  key = value with spaces  \r\nempty=\r\nno separator\r\n",
+			want: map[string]string{"key": "value with spaces", "empty": ""},
+		},
+		{
+			name: "values containing separators",
+			data: "JvmArgs=-Dfoo=bar -Dbaz=qux\nInstanceDir=C:\\Games\\Instances\n",
+			want: map[string]string{"JvmArgs": "-Dfoo=bar -Dbaz=qux", "InstanceDir": `C:\Games\Instances`},
+		},
+		{
+			name: "quotes that are not valid Go strings are stripped",
+			data: "name=\"C:\\Games\\Pack\"\nlone=\"\n",
+			want: map[string]string{"name": `C:\Games\Pack`, "lone": `"`},
+		},
+		{
+			name: "later keys win",
+			data: "name=first\n[Other]\nname=second\n",
+			want: map[string]string{"name": "second"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "instance.cfg")
+			writeTestFile(t, path, test.data)
+
+			got, err := readINI(path)
+			if err != nil {
+				t.Fatalf("readINI: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("readINI =\n%q\nwant\n%q", got, test.want)
+			}
+		})
+	}
+
+	if _, err := readINI(filepath.Join(t.TempDir(), "missing.cfg")); err == nil {
+		t.Error("readINI of a missing file succeeded")
+	}
+}
+
+func TestGetINIOverride(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  map[string]string
+		want string
+	}{
+		{"override on", map[string]string{"OverrideMemory": "true", "MaxMemAlloc": "4096"}, "4096"},
+		{"override off", map[string]string{"OverrideMemory": "false", "MaxMemAlloc": "4096"}, ""},
+		{"no flag in old instances", map[string]string{"MaxMemAlloc": "4096"}, "4096"},
+		{"flag without value", map[string]string{"OverrideMemory": "true"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getINIOverride(test.cfg, "OverrideMemory", "MaxMemAlloc"); got != test.want {
+				t.Errorf("getINIOverride = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadMultiMCInstance(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      string
+		pack     string
+		gameDir  string
+		want     MultiMCInstance
+		wantFail bool
+	}{
+		{
+			name:    "prism fabric instance",
+			cfg:     _prismInstanceCfg,
+			pack:    _prismPackJson,
+			gameDir: ".minecraft",
+			want: MultiMCInstance{
+				Name:             "Fabulously Optimized",
+				GameDirectory:    ".minecraft",
+				MinecraftVersion: "1.21.1",
+				Loader:           LoaderFabric,
+				LoaderVersion:    "0.16.9",
+				MaxMemory:        6144,
+				JVMArguments:     "-XX:+UseG1GC -Dfile.encoding=UTF-8",
+				JavaPath:         "/usr/lib/jvm/java-21-openjdk/bin/java",
+			},
+		},
+		{
+			name:    "old multimc forge instance without override flags",
+			cfg:     "InstanceType=OneSix\nMaxMemAlloc=4096\nJvmArgs=-Xss2M\nMinecraftWinWidth=854\nMinecraftWinHeight=480\n",
+			pack:    `{"components":[{"uid":"net.minecraft","cachedVersion":"1.12.2"},{"uid":"net.minecraftforge","version":"14.23.5.2860"}],"formatVersion":1}`,
+			gameDir: "minecraft",
+			want: MultiMCInstance{
+				Name:             "old multimc forge instance without override flags",
+				GameDirectory:    "minecraft",
+				MinecraftVersion: "1.12.2",
+				Loader:           LoaderForge,
+				LoaderVersion:    "14.23.5.2860",
+				MaxMemory:        4096,
+				JVMArguments:     "-Xss2M",
+				WindowWidth:      854,
+				WindowHeight:     480,
+			},
+		},
+		{
+			name: "neoforge instance without a game directory yet",
+			cfg:  "name=NeoForge\nOverrideMemory=false\nMaxMemAlloc=8192\n",
+			pack: `{"components":[{"uid":"net.minecraft","version":"1.21.1"},{"uid":"net.neoforged","version":"21.1.77"}]}`,
+			want: MultiMCInstance{
+				Name:             "NeoForge",
+				MinecraftVersion: "1.21.1",
+				Loader:           LoaderNeoForge,
+				LoaderVersion:    "21.1.77",
+			},
+		},
+		{
+			name: "vanilla instance",
+			cfg:  "name=Vanilla\n",
+			pack: `{"components":[{"uid":"net.minecraft","version":"1.20.4"}]}`,
+			want: MultiMCInstance{Name: "Vanilla", MinecraftVersion: "1.20.4", Loader: LoaderVanilla},
+		},
+		{
+			name:     "no minecraft component",
+			cfg:      "name=Broken\n",
+			pack:     `{"components":[{"uid":"net.fabricmc.fabric-loader","version":"0.16.9"}]}`,
+			wantFail: true,
+		},
+		{
+			name:     "malformed pack",
+			cfg:      "name=Broken\n",
+			pack:     `{"components":`,
+			wantFail: true,
+		},
+		{
+			name:     "missing instance.cfg",
+			pack:     _prismPackJson,
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), test.name)
+			os.MkdirAll(dir, 0755)
+			if test.cfg != "" {
+				writeTestFile(t, filepath.Join(dir, "instance.cfg"), test.cfg)
+			}
+			writeTestFile(t, filepath.Join(dir, "mmc-pack.json"), test.pack)
+			if test.gameDir != "" {
+				os.MkdirAll(filepath.Join(dir, test.gameDir), 0755)
+			}
+
+			got, err := ReadMultiMCInstance(dir)
+			if test.wantFail {
+				if !errors.Is(err, ErrorInvalidMultiMCInstance) {
+					t.Fatalf("ReadMultiMCInstance = %+v, %v; want ErrorInvalidMultiMCInstance", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadMultiMCInstance: %v", err)
+			}
+
+			want := test.want
+			want.Path = dir
+			if want.GameDirectory != "" {
+				want.GameDirectory = filepath.Join(dir, want.GameDirectory)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ReadMultiMCInstance =\n%+v\nwant\n%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetMultiMCInstancesDirectory(t *testing.T) {
+	absolute := filepath.Join(t.TempDir(), "elsewhere")
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"default", nil, "instances"},
+		{"relative", map[string]string{"prismlauncher.cfg": "[General]\nInstanceDir=my-instances\n"}, "my-instances"},
+		{"absolute", map[string]string{"multimc.cfg": "InstanceDir=" + absolute + "\n"}, absolute},
+		{"empty setting", map[string]string{"polymc.cfg": "InstanceDir=\n"}, "instances"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dataDir := t.TempDir()
+			for name, content := range test.files {
+				writeTestFile(t, filepath.Join(dataDir, name), content)
+			}
+
+			want := test.want
+			if !filepath.IsAbs(want) {
+				want = filepath.Join(dataDir, want)
+			}
+			if got := getMultiMCInstancesDirectory(dataDir); got != want {
+				t.Errorf("getMultiMCInstancesDirectory = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFindMultiMCInstances(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("data directories are only redirected through XDG_DATA_HOME on Linux")
+	}
+
+	home := t.TempDir()
+	dataHome := filepath.Join(home, "data")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	instance := func(dir, name string) {
+		writeTestFile(t, filepath.Join(dir, "instance.cfg"), "name="+name+"\n")
+		writeTestFile(t, filepath.Join(dir, "mmc-pack.json"), `{"components":[{"uid":"net.minecraft","version":"1.21.1"}]}`)
+	}
+	instance(filepath.Join(dataHome, "PrismLauncher", "instances", "b"), "beta")
+	instance(filepath.Join(dataHome, "PrismLauncher", "instances", "a"), "Alpha")
+	instance(filepath.Join(dataHome, "PrismLauncher", "instances", ".tmp"), "Hidden")
+	instance(filepath.Join(dataHome, "PrismLauncher", "instances", "_MMC_TEMP"), "Temporary")
+	os.MkdirAll(filepath.Join(dataHome, "PrismLauncher", "instances", "not-an-instance"), 0755)
+	writeTestFile(t, filepath.Join(dataHome, "multimc", "multimc.cfg"), "InstanceDir=custom\n")
+	instance(filepath.Join(dataHome, "multimc", "custom", "old"), "Old")
+
+	got, err := FindMultiMCInstances()
+	if err != nil {
+		t.Fatalf("FindMultiMCInstances: %v", err)
+	}
+
+	var names, launchers []string
+	for _, instance := range got {
+		names = append(names, instance.Name)
+		launchers = append(launchers, instance.Launcher)
+	}
+	if want := []string{"Alpha", "beta", "Old"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("instances = %v, want %v", names, want)
+	}
+	if want := []string{"PrismLauncher", "PrismLauncher", "MultiMC"}; !reflect.DeepEqual(launchers, want) {
+		t.Errorf("launchers = %v, want %v", launchers, want)
+	}
+}
+
+func TestCopyGameDirectory(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "options.txt"), "fov:0.5")
+	writeTestFile(t, filepath.Join(src, "mods", "sodium.jar"), "sodium")
+	os.MkdirAll(filepath.Join(src, "saves", "empty"), 0755)
+
+	t.Run("copy", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "instance")
+		if err := CopyGameDirectory(src, dst, false); err != nil {
+			t.Fatalf("CopyGameDirectory: %v", err)
+		}
+
+		for name, want := range map[string]string{"options.txt": "fov:0.5", filepath.Join("mods", "sodium.jar"): "sodium"} {
+			if data, err := os.ReadFile(filepath.Join(dst, name)); err != nil || string(data) != want {
+				t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+			}
+		}
+		if info, err := os.Stat(filepath.Join(dst, "saves", "empty")); err != nil || !info.IsDir() {
+			t.Error("empty directories were not copied")
+		}
+
+		// The copy is independent of the source.
+		writeTestFile(t, filepath.Join(dst, "options.txt"), "fov:1.0")
+		if data, _ := os.ReadFile(filepath.Join(src, "options.txt")); string(data) != "fov:0.5" {
+			t.Error("changing the copy changed the source")
+		}
+	})
+
+	t.Run("link", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "instance")
+		os.MkdirAll(dst, 0755)
+		if err := CopyGameDirectory(src, dst, true); err != nil {
+			if runtime.GOOS == "windows" {
+				t.Skipf("symlinks need developer mode on Windows: %v", err)
+			}
+			t.Fatalf("CopyGameDirectory: %v", err)
+		}
+
+		if target, err := os.Readlink(dst); err != nil || target != src {
+			t.Errorf("Readlink = %q, %v; want %q", target, err, src)
+		}
+		if data, err := os.ReadFile(filepath.Join(dst, "mods", "sodium.jar")); err != nil || string(data) != "sodium" {
+			t.Errorf("mods/sodium.jar through the link = %q, %v", data, err)
+		}
+	})
+}
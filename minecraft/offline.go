@@ -0,0 +1,38 @@
+package minecraft
+
+import (
+	"crypto/md5"
+	"errors"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+const (
+	UserTypeMSA    = "msa"
+	UserTypeLegacy = "legacy"
+	UserTypeMojang = "mojang" // what authlib-injector expects for third-party servers
+
+	// OfflineAccessToken is passed to the game for offline accounts; it only has to be non-empty.
+	OfflineAccessToken = "0"
+)
+
+var ErrorInvalidUsername = errors.New("username must be 3 to 16 characters of letters, digits and underscores")
+
+var _usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,16}$`)
+
+func ValidateUsername(username string) error {
+	if !_usernamePattern.MatchString(username) {
+		return ErrorInvalidUsername
+	}
+	return nil
+}
+
+// GetOfflineUUID returns the UUID the game and servers in offline mode derive for a player: Java's
+// UUID.nameUUIDFromBytes("OfflinePlayer:<name>"), an MD5 name-based version 3 UUID without a namespace.
+func GetOfflineUUID(username string) string {
+	sum := md5.Sum([]byte("OfflinePlayer:" + username))
+	sum[6] = sum[6]&0x0f | 0x30
+	sum[8] = sum[8]&0x3f | 0x80
+	return uuid.UUID(sum).String()
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package minecraft
+
+// getWindowsVersion is only called on Windows; the stub keeps the package building elsewhere.
+func getWindowsVersion() string {
+	return ""
+}
@@ -0,0 +1,12 @@
+package minecraft
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+func getWindowsVersion() string {
+	maj, min, _ := windows.RtlGetNtVersionNumbers()
+	return fmt.Sprintf("%d.%d", maj, min)
+}
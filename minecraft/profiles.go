@@ -2,9 +2,11 @@ package minecraft
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -50,60 +52,154 @@ func isVanillaLauncherProfileValid(profile VanillaLauncherProfile) bool {
 	return true
 }
 
-func LoadVanillaLauncherProfiles(minecraftDir string) ([]VanillaLauncherProfile, error) {
-	filePath := filepath.Join(minecraftDir, "launcher_profiles.json")
-	data, err := os.ReadFile(filePath)
+type vanillaLauncherProfilesFile struct {
+	path     string
+	root     map[string]json.RawMessage
+	profiles map[string]map[string]json.RawMessage
+}
+
+var ErrorVanillaProfileNotFound error = errors.New("vanilla launcher profile not found")
+
+// vanillaProfileKeys are the keys this package manages; anything else in a profile is written back untouched.
+var vanillaProfileKeys = []string{"name", "lastVersionId", "gameDir", "javaDir", "javaArgs", "resolution", "icon", "lastUsed", "created"}
+
+func readVanillaLauncherProfilesFile(minecraftDir string, create bool) (*vanillaLauncherProfilesFile, error) {
+	file := &vanillaLauncherProfilesFile{
+		path:     filepath.Join(minecraftDir, "launcher_profiles.json"),
+		root:     map[string]json.RawMessage{},
+		profiles: map[string]map[string]json.RawMessage{},
+	}
+
+	data, err := os.ReadFile(file.path)
 	if err != nil {
+		if create && os.IsNotExist(err) {
+			file.root["version"] = json.RawMessage("3")
+			return file, nil
+		}
 		return nil, fmt.Errorf("failed to read launcher_profiles.json: %w", err)
 	}
 
-	var launcherData VanillaLauncherProfilesJson
-	if err := json.Unmarshal(data, &launcherData); err != nil {
+	if err := json.Unmarshal(data, &file.root); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
 	}
-
-	var profiles []VanillaLauncherProfile
-	for _, value := range launcherData.Profiles {
-		var profile VanillaLauncherProfile
-
-		switch value.Type {
-		case "latest-release":
-			profile.Name = "Latest release"
-		case "latest-snapshot":
-			profile.Name = "Latest snapshot"
-		default:
-			profile.Name = value.Name
+	if raw, found := file.root["profiles"]; found {
+		if err := json.Unmarshal(raw, &file.profiles); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal profiles: %w", err)
 		}
+	}
+	if file.profiles == nil {
+		file.profiles = map[string]map[string]json.RawMessage{}
+	}
 
-		switch value.LastVersionID {
-		case "latest-release":
-			profile.VersionType = "latest-release"
-		case "latest-snapshot":
-			profile.VersionType = "latest-snapshot"
-		default:
-			profile.VersionType = "custom"
-			profile.Version = &value.LastVersionID
-		}
+	return file, nil
+}
 
-		if value.GameDir != "" {
-			profile.GameDirectory = &value.GameDir
-		}
-		if value.JavaDir != "" {
-			profile.JavaExecutable = &value.JavaDir
-		}
-		if value.JavaArgs != "" {
-			profile.JavaArguments = strings.Fields(value.JavaArgs)
-		}
-		if value.Resolution != nil {
-			profile.CustomResolution = value.Resolution
-		}
+func (f *vanillaLauncherProfilesFile) save() error {
+	profiles, err := json.Marshal(f.profiles)
+	if err != nil {
+		return err
+	}
+	f.root["profiles"] = profiles
+
+	out, err := json.MarshalIndent(f.root, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal launcher_profiles.json: %w", err)
+	}
+
+	tmpPath := f.path + ".tmp"
+	if err := os.WriteFile(tmpPath, out, 0644); err != nil {
+		return fmt.Errorf("failed to write launcher_profiles.json: %w", err)
+	}
+	return os.Rename(tmpPath, f.path)
+}
 
+func parseVanillaLauncherProfile(id string, raw map[string]json.RawMessage) (VanillaLauncherProfile, error) {
+	var value VanillaLauncherProfilesJsonProfile
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return VanillaLauncherProfile{}, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return VanillaLauncherProfile{}, err
+	}
+
+	profile := VanillaLauncherProfile{
+		Id:       id,
+		Icon:     value.Icon,
+		Created:  value.Created,
+		LastUsed: value.LastUsed,
+	}
+
+	switch value.Type {
+	case "latest-release":
+		profile.Name = "Latest release"
+	case "latest-snapshot":
+		profile.Name = "Latest snapshot"
+	default:
+		profile.Name = value.Name
+	}
+
+	switch value.LastVersionID {
+	case "latest-release":
+		profile.VersionType = "latest-release"
+	case "latest-snapshot":
+		profile.VersionType = "latest-snapshot"
+	default:
+		profile.VersionType = "custom"
+		profile.Version = &value.LastVersionID
+	}
+
+	if value.GameDir != "" {
+		profile.GameDirectory = &value.GameDir
+	}
+	if value.JavaDir != "" {
+		profile.JavaExecutable = &value.JavaDir
+	}
+	if value.JavaArgs != "" {
+		profile.JavaArguments = strings.Fields(value.JavaArgs)
+	}
+	if value.Resolution != nil {
+		profile.CustomResolution = value.Resolution
+	}
+
+	return profile, nil
+}
+
+func LoadVanillaLauncherProfiles(minecraftDir string) ([]VanillaLauncherProfile, error) {
+	file, err := readVanillaLauncherProfilesFile(minecraftDir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []VanillaLauncherProfile
+	for id, raw := range file.profiles {
+		profile, err := parseVanillaLauncherProfile(id, raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse profile %s: %w", id, err)
+		}
 		profiles = append(profiles, profile)
 	}
 
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].LastUsed > profiles[j].LastUsed
+	})
+
 	return profiles, nil
 }
 
+func GetVanillaLauncherProfile(minecraftDir, id string) (VanillaLauncherProfile, error) {
+	file, err := readVanillaLauncherProfilesFile(minecraftDir, false)
+	if err != nil {
+		return VanillaLauncherProfile{}, err
+	}
+
+	raw, found := file.profiles[id]
+	if !found {
+		return VanillaLauncherProfile{}, ErrorVanillaProfileNotFound
+	}
+	return parseVanillaLauncherProfile(id, raw)
+}
+
 func VanillaLauncherProfileToMinecraftOptions(profile VanillaLauncherProfile) (MinecraftOptions, error) {
 	if !isVanillaLauncherProfileValid(profile) {
 		return MinecraftOptions{}, fmt.Errorf("invalid vanilla launcher profile")
@@ -133,23 +229,7 @@ func VanillaLauncherProfileToMinecraftOptions(profile VanillaLauncherProfile) (M
 }
 
 
-func AddVanillaLauncherProfile(minecraftDir string, profile VanillaLauncherProfile) error {
-	if !isVanillaLauncherProfileValid(profile) {
-		return fmt.Errorf("invalid vanilla launcher profile")
-	}
-
-	filePath := filepath.Join(minecraftDir, "launcher_profiles.json")
-
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read launcher_profiles.json: %w", err)
-	}
-
-	var launcher VanillaLauncherProfilesJson
-	if err := json.Unmarshal(data, &launcher); err != nil {
-		return fmt.Errorf("failed to parse launcher_profiles.json: %w", err)
-	}
-
+func toVanillaLauncherProfilesJsonProfile(profile VanillaLauncherProfile) (VanillaLauncherProfilesJsonProfile, error) {
 	var newProfile VanillaLauncherProfilesJsonProfile
 	newProfile.Name = profile.Name
 
@@ -161,7 +241,7 @@ func AddVanillaLauncherProfile(minecraftDir string, profile VanillaLauncherProfi
 	case "custom":
 		newProfile.LastVersionID = derefStr(profile.Version)
 	default:
-		return fmt.Errorf("unsupported versionType: %s", profile.VersionType)
+		return newProfile, fmt.Errorf("unsupported versionType: %s", profile.VersionType)
 	}
 
 	if profile.GameDirectory != nil {
@@ -176,32 +256,124 @@ func AddVanillaLauncherProfile(minecraftDir string, profile VanillaLauncherProfi
 	if profile.CustomResolution != nil {
 		newProfile.Resolution = profile.CustomResolution
 	}
+	newProfile.Icon = profile.Icon
+	newProfile.Created = profile.Created
+	newProfile.LastUsed = profile.LastUsed
+
+	return newProfile, nil
+}
+
+// mergeVanillaLauncherProfile writes the managed keys of profile into raw and leaves every other key as it was.
+func mergeVanillaLauncherProfile(raw map[string]json.RawMessage, profile VanillaLauncherProfile) error {
+	newProfile, err := toVanillaLauncherProfilesJsonProfile(profile)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(newProfile)
+	if err != nil {
+		return err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	var profileType string
+	json.Unmarshal(raw["type"], &profileType)
+
+	for _, key := range vanillaProfileKeys {
+		switch {
+		case key == "name" && (profileType == "latest-release" || profileType == "latest-snapshot"):
+			continue
+		case (key == "created" || key == "lastUsed" || key == "icon") && values[key] == nil:
+			continue
+		case values[key] == nil:
+			delete(raw, key)
+		default:
+			raw[key] = values[key]
+		}
+	}
+	if _, found := raw["type"]; !found {
+		raw["type"] = json.RawMessage(`"custom"`)
+	}
+
+	return nil
+}
+
+func CreateVanillaLauncherProfile(minecraftDir string, profile VanillaLauncherProfile) (string, error) {
+	if !isVanillaLauncherProfileValid(profile) {
+		return "", fmt.Errorf("invalid vanilla launcher profile")
+	}
+
+	file, err := readVanillaLauncherProfilesFile(minecraftDir, true)
+	if err != nil {
+		return "", err
+	}
 
 	now := time.Now().Format(time.RFC3339)
-	newProfile.Created = now
-	newProfile.LastUsed = now
-	newProfile.Type = "custom"
+	if profile.Created == "" {
+		profile.Created = now
+	}
+	if profile.LastUsed == "" {
+		profile.LastUsed = now
+	}
+
+	raw := map[string]json.RawMessage{}
+	if err := mergeVanillaLauncherProfile(raw, profile); err != nil {
+		return "", err
+	}
 
 	var key string
 	for {
-		key = uuid.NewString()
-		if _, exists := launcher.Profiles[key]; !exists {
+		key = strings.ReplaceAll(uuid.NewString(), "-", "")
+		if _, exists := file.profiles[key]; !exists {
 			break
 		}
 	}
+	file.profiles[key] = raw
 
-	launcher.Profiles[key] = newProfile
+	return key, file.save()
+}
+
+func AddVanillaLauncherProfile(minecraftDir string, profile VanillaLauncherProfile) error {
+	_, err := CreateVanillaLauncherProfile(minecraftDir, profile)
+	return err
+}
 
-	out, err := json.MarshalIndent(launcher, "", "    ")
+func UpdateVanillaLauncherProfile(minecraftDir, id string, profile VanillaLauncherProfile) error {
+	if !isVanillaLauncherProfileValid(profile) {
+		return fmt.Errorf("invalid vanilla launcher profile")
+	}
+
+	file, err := readVanillaLauncherProfilesFile(minecraftDir, false)
 	if err != nil {
-		return fmt.Errorf("failed to marshal launcher_profiles.json: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(filePath, out, 0644); err != nil {
-		return fmt.Errorf("failed to write launcher_profiles.json: %w", err)
+	raw, found := file.profiles[id]
+	if !found {
+		return ErrorVanillaProfileNotFound
+	}
+	if err := mergeVanillaLauncherProfile(raw, profile); err != nil {
+		return err
 	}
 
-	return nil
+	return file.save()
+}
+
+func DeleteVanillaLauncherProfile(minecraftDir, id string) error {
+	file, err := readVanillaLauncherProfilesFile(minecraftDir, false)
+	if err != nil {
+		return err
+	}
+
+	if _, found := file.profiles[id]; !found {
+		return ErrorVanillaProfileNotFound
+	}
+	delete(file.profiles, id)
+
+	return file.save()
 }
 
 func GetVanillaLauncherProfileVersion(profile VanillaLauncherProfile, latestVersionFunc func() map[string]string) (string, error) {
@@ -0,0 +1,162 @@
+package minecraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+const (
+	DefaultMinecraftServicesURL = "https://api.minecraftservices.com"
+
+	SkinVariantClassic = "classic"
+	SkinVariantSlim    = "slim"
+)
+
+var (
+	ErrorInvalidSkin        = errors.New("skin must be a 64x64 or 64x32 PNG image")
+	ErrorInvalidSkinVariant = errors.New("skin variant must be classic or slim")
+)
+
+type SkinOptions struct {
+	APIURL string `json:"apiURL,omitempty"` // defaults to DefaultMinecraftServicesURL
+}
+
+func getSkinAPIURL(options SkinOptions) string {
+	if options.APIURL != "" {
+		return strings.TrimSuffix(options.APIURL, "/")
+	}
+	return DefaultMinecraftServicesURL
+}
+
+// ValidateSkin checks the image before it is uploaded, so the user gets a clear error instead of a rejected request.
+func ValidateSkin(data []byte) error {
+	// Decode the whole image: a valid header can still hide truncated or corrupt pixel data.
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ErrorInvalidSkin
+	}
+	size := img.Bounds().Size()
+	if size.X != 64 || (size.Y != 64 && size.Y != 32) {
+		return ErrorInvalidSkin
+	}
+	return nil
+}
+
+func validateSkinVariant(variant string) error {
+	if variant != SkinVariantClassic && variant != SkinVariantSlim {
+		return ErrorInvalidSkinVariant
+	}
+	return nil
+}
+
+// sendProfileRequest calls a profile endpoint and returns the updated profile it answers with.
+func sendProfileRequest(method, endpoint, accessToken, contentType string, body io.Reader, options SkinOptions) (*MinecraftProfileResponse, error) {
+	req, err := http.NewRequest(method, getSkinAPIURL(options)+endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("User-Agent", getUserAgent())
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var servicesErr minecraftServicesErrorResponse
+		json.NewDecoder(resp.Body).Decode(&servicesErr)
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, ErrAccessTokenExpired.with(resp.StatusCode, servicesErr.ErrorMessage)
+		}
+		return nil, getMinecraftServicesError(resp.StatusCode, servicesErr)
+	}
+
+	var profile MinecraftProfileResponse
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil || profile.ID == "" {
+		// Some endpoints answer with an empty body; fetch the profile instead.
+		fetched, err := getProfile(getSkinAPIURL(options), accessToken)
+		if err != nil {
+			return nil, err
+		}
+		if fetched.Error != "" {
+			return nil, fmt.Errorf("failed to fetch profile: %s: %s", fetched.Error, fetched.ErrorMessage)
+		}
+		return fetched, nil
+	}
+	return &profile, nil
+}
+
+func sendProfileJSON(method, endpoint, accessToken string, body any, options SkinOptions) (*MinecraftProfileResponse, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return sendProfileRequest(method, endpoint, accessToken, "application/json", bytes.NewReader(jsonBody), options)
+}
+
+func UploadSkin(accessToken, variant string, data []byte, options SkinOptions) (*MinecraftProfileResponse, error) {
+	if err := validateSkinVariant(variant); err != nil {
+		return nil, err
+	}
+	if err := ValidateSkin(data); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("variant", variant); err != nil {
+		return nil, err
+	}
+	part, err := writer.CreateFormFile("file", "skin.png")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return sendProfileRequest("POST", "/minecraft/profile/skins", accessToken, writer.FormDataContentType(), &body, options)
+}
+
+func SetSkinFromURL(accessToken, variant, skinURL string, options SkinOptions) (*MinecraftProfileResponse, error) {
+	if err := validateSkinVariant(variant); err != nil {
+		return nil, err
+	}
+
+	body := map[string]string{
+		"variant": variant,
+		"url":     skinURL,
+	}
+	return sendProfileJSON("POST", "/minecraft/profile/skins", accessToken, body, options)
+}
+
+// ResetSkin goes back to the default skin for the profile.
+func ResetSkin(accessToken string, options SkinOptions) (*MinecraftProfileResponse, error) {
+	return sendProfileRequest("DELETE", "/minecraft/profile/skins/active", accessToken, "", nil, options)
+}
+
+func ShowCape(accessToken, capeId string, options SkinOptions) (*MinecraftProfileResponse, error) {
+	body := map[string]string{
+		"capeId": capeId,
+	}
+	return sendProfileJSON("PUT", "/minecraft/profile/capes/active", accessToken, body, options)
+}
+
+func HideCape(accessToken string, options SkinOptions) (*MinecraftProfileResponse, error) {
+	return sendProfileRequest("DELETE", "/minecraft/profile/capes/active", accessToken, "", nil, options)
+}
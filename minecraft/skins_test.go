@@ -0,0 +1,263 @@
+package minecraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newTestSkin(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		img.Set(x, x%height, color.NRGBA{R: 0x8b, G: 0x5a, B: 0x2b, A: 0xff})
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidateSkin(t *testing.T) {
+	skin := newTestSkin(t, 64, 64)
+
+	var jpegSkin bytes.Buffer
+	if err := jpeg.Encode(&jpegSkin, image.NewRGBA(image.Rect(0, 0, 64, 64)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		data  []byte
+		valid bool
+	}{
+		{"64x64", skin, true},
+		{"legacy 64x32", newTestSkin(t, 64, 32), true},
+		{"HD 128x128", newTestSkin(t, 128, 128), false},
+		{"64x48", newTestSkin(t, 64, 48), false},
+		{"32x64", newTestSkin(t, 32, 64), false},
+		{"header only", skin[:33], false},
+		{"truncated pixel data", skin[:len(skin)-20], false},
+		{"jpeg", jpegSkin.Bytes(), false},
+		{"empty", nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateSkin(test.data)
+			if test.valid && err != nil {
+				t.Errorf("ValidateSkin = %v, want nil", err)
+			}
+			if !test.valid && !errors.Is(err, ErrorInvalidSkin) {
+				t.Errorf("ValidateSkin = %v, want ErrorInvalidSkin", err)
+			}
+		})
+	}
+}
+
+var _testSkinProfile = MinecraftProfileResponse{
+	ID:   "069a79f444e94726a5befca90e38aaf5",
+	Name: "Notch",
+	Skins: []MinecraftProfileSkin{{
+		MinecraftProfileInfo: MinecraftProfileInfo{ID: "skin-1", State: "ACTIVE", URL: "http://textures.minecraft.net/texture/1"},
+		Variant:              "SLIM",
+	}},
+	Capes: []MinecraftProfileCape{{
+		MinecraftProfileInfo: MinecraftProfileInfo{ID: "cape-1", State: "ACTIVE", URL: "http://textures.minecraft.net/texture/2"},
+		Alias:                "Migrator",
+	}},
+}
+
+type profileRequest struct {
+	Method      string
+	Path        string
+	ContentType string
+	Variant     string
+	File        []byte
+	JSON        map[string]string
+}
+
+// newProfileServer stands in for the Minecraft Services profile endpoints. respond decides the status and body of
+// every change request; GET /minecraft/profile always answers with _testSkinProfile.
+func newProfileServer(t *testing.T, respond func(w http.ResponseWriter), requests *[]profileRequest) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mc-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodGet && r.URL.Path == "/minecraft/profile" {
+			json.NewEncoder(w).Encode(_testSkinProfile)
+			return
+		}
+
+		request := profileRequest{Method: r.Method, Path: r.URL.Path}
+		request.ContentType, _, _ = mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch request.ContentType {
+		case "multipart/form-data":
+			request.Variant = r.FormValue("variant")
+			if file, _, err := r.FormFile("file"); err == nil {
+				request.File, _ = io.ReadAll(file)
+				file.Close()
+			}
+		case "application/json":
+			json.NewDecoder(r.Body).Decode(&request.JSON)
+		}
+		*requests = append(*requests, request)
+
+		respond(w)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProfileChanges(t *testing.T) {
+	skin := newTestSkin(t, 64, 64)
+
+	respondProfile := func(w http.ResponseWriter) { json.NewEncoder(w).Encode(_testSkinProfile) }
+	respondEmpty := func(w http.ResponseWriter) {}
+	respondStatus := func(status int, body string) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		change  func(token string, options SkinOptions) (*MinecraftProfileResponse, error)
+		respond func(w http.ResponseWriter)
+		want    []profileRequest
+		wantErr error
+	}{
+		{
+			name: "upload",
+			change: func(token string, options SkinOptions) (*MinecraftProfileResponse, error) {
+				return UploadSkin(token, SkinVariantSlim, skin, options)
+			},
+			respond: respondProfile,
+			want:    []profileRequest{{Method: "POST", Path: "/minecraft/profile/skins", ContentType: "multipart/form-data", Variant: "slim", File: skin}},
+		},
+		{
+			name: "upload with an empty response",
+			change: func(token string, options SkinOptions) (*MinecraftProfileResponse, error) {
+				return UploadSkin(token, SkinVariantClassic, skin, options)
+			},
+			respond: respondEmpty,
+			want:    []profileRequest{{Method: "POST", Path: "/minecraft/profile/skins", ContentType: "multipart/form-data", Variant: "classic", File: skin}},
+		},
+		{
+			name: "upload of an invalid skin is not sent",
+			change: func(token string, options SkinOptions) (*MinecraftProfileResponse, error) {
+				return UploadSkin(token, SkinVariantClassic, newTestSkin(t, 128, 128), options)
+			},
+			respond: respondProfile,
+			wantErr: ErrorInvalidSkin,
+		},
+		{
+			name: "upload with an invalid variant is not sent",
+			change: func(token string, options SkinOptions) (*MinecraftProfileResponse, error) {
+				return UploadSkin(token, "wide", skin, options)
+			},
+			respond: respondProfile,
+			wantErr: ErrorInvalidSkinVariant,
+		},
+		{
+			name: "skin from url",
+			change: func(token string, options SkinOptions) (*MinecraftProfileResponse, error) {
+				return SetSkinFromURL(token, SkinVariantClassic, "https://example.com/skin.png", options)
+			},
+			respond: respondProfile,
+			want: []profileRequest{{Method: "POST", Path: "/minecraft/profile/skins", ContentType: "application/json",
+				JSON: map[string]string{"variant": "classic", "url": "https://example.com/skin.png"}}},
+		},
+		{
+			name:    "reset skin",
+			change:  ResetSkin,
+			respond: respondProfile,
+			want:    []profileRequest{{Method: "DELETE", Path: "/minecraft/profile/skins/active"}},
+		},
+		{
+			name: "show cape",
+			change: func(token string, options SkinOptions) (*MinecraftProfileResponse, error) {
+				return ShowCape(token, "cape-1", options)
+			},
+			respond: respondProfile,
+			want:    []profileRequest{{Method: "PUT", Path: "/minecraft/profile/capes/active", ContentType: "application/json", JSON: map[string]string{"capeId": "cape-1"}}},
+		},
+		{
+			name:    "hide cape",
+			change:  HideCape,
+			respond: respondEmpty,
+			want:    []profileRequest{{Method: "DELETE", Path: "/minecraft/profile/capes/active"}},
+		},
+		{
+			name:    "expired token",
+			token:   "expired-token",
+			change:  ResetSkin,
+			respond: respondProfile,
+			wantErr: ErrAccessTokenExpired,
+		},
+		{
+			name:    "rate limited",
+			change:  HideCape,
+			respond: respondStatus(http.StatusTooManyRequests, `{"errorMessage":"Too many requests"}`),
+			want:    []profileRequest{{Method: "DELETE", Path: "/minecraft/profile/capes/active"}},
+			wantErr: ErrTooManyRequests,
+		},
+		{
+			name: "rejected skin",
+			change: func(token string, options SkinOptions) (*MinecraftProfileResponse, error) {
+				return SetSkinFromURL(token, SkinVariantClassic, "https://example.com/missing.png", options)
+			},
+			respond: respondStatus(http.StatusBadRequest, `{"path":"/minecraft/profile/skins","errorMessage":"Could not fetch skin"}`),
+			want: []profileRequest{{Method: "POST", Path: "/minecraft/profile/skins", ContentType: "application/json",
+				JSON: map[string]string{"variant": "classic", "url": "https://example.com/missing.png"}}},
+			wantErr: ErrMinecraftAuthFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests []profileRequest
+			server := newProfileServer(t, test.respond, &requests)
+
+			token := test.token
+			if token == "" {
+				token = "mc-token"
+			}
+			profile, err := test.change(token, SkinOptions{APIURL: server.URL + "/"})
+			if !reflect.DeepEqual(requests, test.want) {
+				t.Errorf("requests =\n%+v\nwant\n%+v", requests, test.want)
+			}
+
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("err = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(*profile, _testSkinProfile) {
+				t.Errorf("profile = %+v, want %+v", *profile, _testSkinProfile)
+			}
+		})
+	}
+}
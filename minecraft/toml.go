@@ -0,0 +1,410 @@
+package minecraft
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tomlParser struct {
+	data string
+	pos  int
+}
+
+func parseTOML(data string) (map[string]any, error) {
+	p := &tomlParser{data: strings.ReplaceAll(data, "\r\n", "\n")}
+	root := make(map[string]any)
+	current := root
+
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return root, nil
+		}
+
+		if p.peek() == '[' {
+			arrayTable := strings.HasPrefix(p.data[p.pos:], "[[")
+			if arrayTable {
+				p.pos += 2
+			} else {
+				p.pos++
+			}
+
+			keys, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+
+			p.skipBlank(false)
+			closing := "]"
+			if arrayTable {
+				closing = "]]"
+			}
+			if !strings.HasPrefix(p.data[p.pos:], closing) {
+				return nil, p.errorf("expected %s", closing)
+			}
+			p.pos += len(closing)
+
+			current, err = tomlOpenTable(root, keys, arrayTable)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			keys, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+
+			p.skipBlank(false)
+			if p.eof() || p.peek() != '=' {
+				return nil, p.errorf("expected =")
+			}
+			p.pos++
+			p.skipBlank(false)
+
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+
+			table, err := tomlOpenTable(current, keys[:len(keys)-1], false)
+			if err != nil {
+				return nil, err
+			}
+			table[keys[len(keys)-1]] = value
+		}
+
+		p.skipBlank(false)
+		if !p.eof() && p.peek() != '\n' {
+			return nil, p.errorf("unexpected character %q", p.peek())
+		}
+	}
+}
+
+func tomlOpenTable(root map[string]any, keys []string, arrayTable bool) (map[string]any, error) {
+	current := root
+	for i, key := range keys {
+		last := i == len(keys)-1
+
+		switch existing := current[key].(type) {
+		case nil:
+			if last && arrayTable {
+				table := make(map[string]any)
+				current[key] = []any{table}
+				return table, nil
+			}
+			table := make(map[string]any)
+			current[key] = table
+			current = table
+		case map[string]any:
+			if last && arrayTable {
+				return nil, fmt.Errorf("toml: %s is not an array of tables", key)
+			}
+			current = existing
+		case []any:
+			if last && arrayTable {
+				table := make(map[string]any)
+				current[key] = append(existing, table)
+				return table, nil
+			}
+			if len(existing) == 0 {
+				return nil, fmt.Errorf("toml: %s is an empty array", key)
+			}
+			table, ok := existing[len(existing)-1].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("toml: %s is not a table", key)
+			}
+			current = table
+		default:
+			return nil, fmt.Errorf("toml: %s is not a table", key)
+		}
+	}
+	return current, nil
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *tomlParser) peek() byte {
+	return p.data[p.pos]
+}
+
+func (p *tomlParser) errorf(format string, args ...any) error {
+	line := strings.Count(p.data[:min(p.pos, len(p.data))], "\n") + 1
+	return fmt.Errorf("toml: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) skipBlank(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, p.errorf("unexpected end of key")
+		}
+
+		var key string
+		var err error
+		switch p.peek() {
+		case '"':
+			key, err = p.parseBasicString()
+		case '\'':
+			key, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for !p.eof() && isTOMLBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			key = p.data[start:p.pos]
+			if key == "" {
+				return nil, p.errorf("invalid key")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+
+		p.skipBlank(false)
+		if p.eof() || p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) parseValue() (any, error) {
+	if p.eof() {
+		return nil, p.errorf("missing value")
+	}
+
+	rest := p.data[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, `"""`):
+		return p.parseMultilineString(`"""`)
+	case strings.HasPrefix(rest, "'''"):
+		return p.parseMultilineString("'''")
+	case rest[0] == '"':
+		return p.parseBasicString()
+	case rest[0] == '\'':
+		return p.parseLiteralString()
+	case rest[0] == '[':
+		return p.parseArray()
+	case rest[0] == '{':
+		return p.parseInlineTable()
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+		p.pos++
+	}
+	token := p.data[start:p.pos]
+
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if i, err := strconv.ParseInt(strings.ReplaceAll(token, "_", ""), 0, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64); err == nil {
+		return f, nil
+	}
+	if token == "" {
+		return nil, p.errorf("invalid value")
+	}
+	return token, nil
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++
+	var sb strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\n':
+			return "", p.errorf("unterminated string")
+		case '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+			continue
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *tomlParser) parseEscape(sb *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return p.errorf("invalid escape")
+	}
+
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'n':
+		sb.WriteByte('\n')
+	case 't':
+		sb.WriteByte('\t')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case '"', '\\':
+		sb.WriteByte(c)
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.data) {
+			return p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.data[p.pos:p.pos+size], 16, 32)
+		if err != nil {
+			return p.errorf("invalid unicode escape")
+		}
+		sb.WriteRune(rune(code))
+		p.pos += size
+	case '\n':
+		for !p.eof() && strings.ContainsRune(" \t\r\n", rune(p.peek())) {
+			p.pos++
+		}
+	default:
+		return p.errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++
+	end := strings.IndexAny(p.data[p.pos:], "'\n")
+	if end < 0 || p.data[p.pos+end] != '\'' {
+		return "", p.errorf("unterminated string")
+	}
+	value := p.data[p.pos : p.pos+end]
+	p.pos += end + 1
+	return value, nil
+}
+
+func (p *tomlParser) parseMultilineString(delimiter string) (string, error) {
+	p.pos += len(delimiter)
+	if !p.eof() && p.peek() == '\n' {
+		p.pos++
+	}
+
+	var sb strings.Builder
+	for !p.eof() {
+		if strings.HasPrefix(p.data[p.pos:], delimiter) {
+			p.pos += len(delimiter)
+			return sb.String(), nil
+		}
+		if delimiter == `"""` && p.peek() == '\\' {
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+			continue
+		}
+		sb.WriteByte(p.peek())
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *tomlParser) parseArray() ([]any, error) {
+	p.pos++
+	values := []any{}
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return values, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		p.skipBlank(true)
+		if !p.eof() && p.peek() == ',' {
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (map[string]any, error) {
+	p.pos++
+	table := make(map[string]any)
+	for {
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, p.errorf("unterminated inline table")
+		}
+		if p.peek() == '}' {
+			p.pos++
+			return table, nil
+		}
+
+		keys, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		p.skipBlank(false)
+		if p.eof() || p.peek() != '=' {
+			return nil, p.errorf("expected =")
+		}
+		p.pos++
+		p.skipBlank(false)
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		target, err := tomlOpenTable(table, keys[:len(keys)-1], false)
+		if err != nil {
+			return nil, err
+		}
+		target[keys[len(keys)-1]] = value
+
+		p.skipBlank(false)
+		if !p.eof() && p.peek() == ',' {
+			p.pos++
+		}
+	}
+}
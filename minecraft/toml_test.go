@@ -0,0 +1,329 @@
+package minecraft
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// _forgeModsToml follows the mods.toml shipped with the Forge 1.20.1 MDK, comments included.
+const _forgeModsToml = `# This is an example mods.toml file. It contains the data relating to the loading mods.
+modLoader="javafml" #mandatory
+loaderVersion="[47,)" #mandatory This is typically bumped every Minecraft version by Forge.
+license="All Rights Reserved"
+issueTrackerURL="https://change.me.to.your.issue.tracker.example.invalid/" #optional
+[[mods]] #mandatory
+modId="examplemod" #mandatory
+version="${file.jarVersion}" #mandatory
+displayName="Example Mod" #mandatory
+logoFile="examplemod.png" #optional
+credits="Thanks for this example mod goes to Java" #optional
+authors="Love, Cheese and small house plants" #optional
+displayTest="MATCH_VERSION"
+description='''
+This is a long form description of the mod. You can write whatever you want here
+
+Have some lorem ipsum.
+'''
+[[dependencies.examplemod]] #optional
+    modId="forge" #mandatory
+    mandatory=true #mandatory
+    versionRange="[47,)" #mandatory
+    ordering="NONE"
+    side="BOTH"
+[[dependencies.examplemod]]
+    modId="minecraft"
+    mandatory=true
+    versionRange="[1.20.1,1.21)"
+    ordering="NONE"
+    side="BOTH"
+`
+
+// _neoForgeModsToml follows neoforge.mods.toml from the NeoForge 1.21 MDK, with the mixin, access
+// transformer and mod property sections the template leaves commented out filled in.
+const _neoForgeModsToml = "modLoader=\"javafml\"\r\n" + `loaderVersion="[4,)"
+license='MIT'
+
+[[mods]]
+modId="examplemod"
+version="1.0.0"
+displayName="Example Mod"
+authors="Tom, Jerry"
+description="""
+Line one \
+    continued.\nLine two with a \"quote\" and \u00e9."""
+
+[[mixins]]
+config="examplemod.mixins.json"
+
+[[accessTransformers]]
+file="META-INF/accesstransformer.cfg"
+
+[[dependencies.examplemod]]
+    modId="neoforge"
+    type="required"
+    versionRange="[21.0.0-beta,)"
+    ordering="NONE"
+    side="BOTH"
+
+[[dependencies.examplemod]]
+    modId="jei"
+    type="optional"
+    versionRange="[19,)"
+    ordering="AFTER"
+    side="CLIENT"
+
+[modproperties.examplemod]
+catalogueItemIcon = { item = "minecraft:diamond", count = 1, "nbt".empty = true }
+tags = [ "utility",
+  'magic', # trailing comment
+]
+weight = 1_000
+ratio = 0.5
+`
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[string]any
+	}{
+		{
+			name: "forge mods.toml",
+			data: _forgeModsToml,
+			want: map[string]any{
+				"modLoader":       "javafml",
+				"loaderVersion":   "[47,)",
+				"license":         "All Rights Reserved",
+				"issueTrackerURL": "https://change.me.to.your.issue.tracker.example.invalid/",
+				"mods": []any{
+					map[string]any{
+						"modId":       "examplemod",
+						"version":     "${file.jarVersion}",
+						"displayName": "Example Mod",
+						"logoFile":    "examplemod.png",
+						"credits":     "Thanks for this example mod goes to Java",
+						"authors":     "Love, Cheese and small house plants",
+						"displayTest": "MATCH_VERSION",
+						"description": "This is a long form description of the mod. You can write whatever you want here\n\nHave some lorem ipsum.\n",
+					},
+				},
+				"dependencies": map[string]any{
+					"examplemod": []any{
+						map[string]any{"modId": "forge", "mandatory": true, "versionRange": "[47,)", "ordering": "NONE", "side": "BOTH"},
+						map[string]any{"modId": "minecraft", "mandatory": true, "versionRange": "[1.20.1,1.21)", "ordering": "NONE", "side": "BOTH"},
+					},
+				},
+			},
+		},
+		{
+			name: "neoforge mods.toml",
+			data: _neoForgeModsToml,
+			want: map[string]any{
+				"modLoader":     "javafml",
+				"loaderVersion": "[4,)",
+				"license":       "MIT",
+				"mods": []any{
+					map[string]any{
+						"modId":       "examplemod",
+						"version":     "1.0.0",
+						"displayName": "Example Mod",
+						"authors":     "Tom, Jerry",
+						"description": "Line one continued.\nLine two with a \"quote\" and \u00e9.",
+					},
+				},
+				"mixins":             []any{map[string]any{"config": "examplemod.mixins.json"}},
+				"accessTransformers": []any{map[string]any{"file": "META-INF/accesstransformer.cfg"}},
+				"dependencies": map[string]any{
+					"examplemod": []any{
+						map[string]any{"modId": "neoforge", "type": "required", "versionRange": "[21.0.0-beta,)", "ordering": "NONE", "side": "BOTH"},
+						map[string]any{"modId": "jei", "type": "optional", "versionRange": "[19,)", "ordering": "AFTER", "side": "CLIENT"},
+					},
+				},
+				"modproperties": map[string]any{
+					"examplemod": map[string]any{
+						"catalogueItemIcon": map[string]any{
+							"item":  "minecraft:diamond",
+							"count": int64(1),
+							"nbt":   map[string]any{"empty": true},
+						},
+						"tags":   []any{"utility", "magic"},
+						"weight": int64(1000),
+						"ratio":  0.5,
+					},
+				},
+			},
+		},
+		{
+			name: "dotted keys and quoted keys",
+			data: "a.b = 1\n\"c.d\" = 'x'\n[e]\nf.g = false\n",
+			want: map[string]any{
+				"a":   map[string]any{"b": int64(1)},
+				"c.d": "x",
+				"e":   map[string]any{"f": map[string]any{"g": false}},
+			},
+		},
+		{
+			name: "nested arrays and inline tables",
+			data: "points = [ { x = 1, y = 2 }, { x = 3, y = 4 } ]\nmatrix = [[1, 2], [\"a\"]]\nempty = []\n",
+			want: map[string]any{
+				"points": []any{
+					map[string]any{"x": int64(1), "y": int64(2)},
+					map[string]any{"x": int64(3), "y": int64(4)},
+				},
+				"matrix": []any{[]any{int64(1), int64(2)}, []any{"a"}},
+				"empty":  []any{},
+			},
+		},
+		{
+			name: "subtable of the last array entry",
+			data: "[[mods]]\nmodId = \"a\"\n[[mods]]\nmodId = \"b\"\n[mods.extra]\nkey = 'v'\n",
+			want: map[string]any{
+				"mods": []any{
+					map[string]any{"modId": "a"},
+					map[string]any{"modId": "b", "extra": map[string]any{"key": "v"}},
+				},
+			},
+		},
+		{
+			name: "literal strings keep backslashes",
+			data: "path = 'C:\\mods\\${file.jarVersion}'\nraw = '''\nline \\n one\n'''\n",
+			want: map[string]any{
+				"path": `C:\mods\${file.jarVersion}`,
+				"raw":  "line \\n one\n",
+			},
+		},
+		{
+			name: "empty document",
+			data: "# only a comment\n\n",
+			want: map[string]any{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTOML(test.data)
+			if err != nil {
+				t.Fatalf("parseTOML: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseTOML =\n%#v\nwant\n%#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseTOMLMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"unterminated string", "modId=\"examplemod\nversion=\"1\"\n", "line 1: unterminated string"},
+		{"unterminated literal string", "modId='examplemod\n", "unterminated string"},
+		{"unterminated multiline string", "description='''\nno end\n", "unterminated string"},
+		{"missing equals", "[[mods]]\nmodId \"examplemod\"\n", "line 2: expected ="},
+		{"missing value", "modId=\n", "invalid value"},
+		{"unclosed table header", "[[mods]\nmodId=\"a\"\n", "expected ]]"},
+		{"unterminated array", "tags = [\"a\", \"b\"\n", "unterminated array"},
+		{"unterminated inline table", "icon = { item = \"a\"", "unterminated inline table"},
+		{"invalid escape", "name = \"a\\qb\"\n", "invalid escape"},
+		{"invalid unicode escape", "name = \"\\u12\"\n", "invalid unicode escape"},
+		{"two values on one line", "modId=\"a\" version=\"1\"\n", "unexpected character"},
+		{"table redefined as array", "[mods]\nmodId=\"a\"\n[[mods]]\n", "not an array of tables"},
+		{"value used as table", "mods = 1\n[mods.extra]\n", "not a table"},
+		{"invalid key", "= 1\n", "invalid key"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTOML(test.data)
+			if err == nil {
+				t.Fatalf("parseTOML(%q) = %v, want an error", test.data, got)
+			}
+			if !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("parseTOML(%q) error = %q, want it to contain %q", test.data, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func newTestJar(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestParseModsToml(t *testing.T) {
+	t.Run("forge with jar version placeholder", func(t *testing.T) {
+		r := newTestJar(t, map[string]string{
+			"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\r\nImplementation-Version: 1.4.2\r\n",
+		})
+
+		var info ModInfo
+		if err := parseModsToml(r, []byte(_forgeModsToml), &info); err != nil {
+			t.Fatalf("parseModsToml: %v", err)
+		}
+		if info.Id != "examplemod" || info.Name != "Example Mod" || info.Loader != LoaderForge {
+			t.Errorf("parseModsToml = %+v, want examplemod on Forge", info)
+		}
+		if info.Version != "1.4.2" {
+			t.Errorf("Version = %q, want the manifest's Implementation-Version", info.Version)
+		}
+		if want := []string{"Love", "Cheese and small house plants"}; !reflect.DeepEqual(info.Authors, want) {
+			t.Errorf("Authors = %q, want %q", info.Authors, want)
+		}
+		if info.MinecraftVersion != "[1.20.1,1.21)" {
+			t.Errorf("MinecraftVersion = %q", info.MinecraftVersion)
+		}
+	})
+
+	t.Run("neoforge dependency types", func(t *testing.T) {
+		r := newTestJar(t, nil)
+
+		info := ModInfo{Loader: LoaderNeoForge}
+		if err := parseModsToml(r, []byte(_neoForgeModsToml), &info); err != nil {
+			t.Fatalf("parseModsToml: %v", err)
+		}
+		if info.Loader != LoaderNeoForge || info.Version != "1.0.0" {
+			t.Errorf("parseModsToml = %+v, want version 1.0.0 on NeoForge", info)
+		}
+
+		types := map[string]string{}
+		for _, dependency := range info.Dependencies {
+			types[dependency.Id] = dependency.Type
+		}
+		if types["jei"] != ModDependencyOptional {
+			t.Errorf("jei dependency = %q, want %q", types["jei"], ModDependencyOptional)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		var info ModInfo
+		if err := parseModsToml(newTestJar(t, nil), []byte("[[mods]\nmodId=\"a\""), &info); err == nil {
+			t.Error("parseModsToml accepted a malformed file")
+		}
+		if err := parseModsToml(newTestJar(t, nil), []byte("modLoader=\"javafml\"\n"), &info); err == nil {
+			t.Error("parseModsToml accepted a file without mods")
+		}
+	})
+}
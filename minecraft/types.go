@@ -113,7 +113,7 @@ type ClientJson struct{
 	Time 					string 	`json:"time"`
 	Type 					string 	`json:"type"`
 	ComplianceLevel 		int 	`json:"complianceLevel"`
-	InheritsFrom 			string 	`json:"-"`
+	InheritsFrom 			string 	`json:"inheritsFrom"`
 }
 
 type versionListManifestJsonVersion struct {
@@ -249,6 +249,7 @@ type VanillaLauncherProfilesJson struct {
 }
 
 type VanillaLauncherProfile struct {
+	Id              string     `json:"id,omitempty"`
 	Name            string     `json:"name"`
 	VersionType     string     `json:"versionType"` // latest-release | latest-snapshot | custom
 	Version         *string    `json:"version,omitempty"`
@@ -256,6 +257,9 @@ type VanillaLauncherProfile struct {
 	JavaExecutable  *string    `json:"javaExecutable,omitempty"`
 	JavaArguments   []string   `json:"javaArguments,omitempty"`
 	CustomResolution *Resolution `json:"customResolution,omitempty"`
+	Icon            string     `json:"icon,omitempty"`
+	Created         string     `json:"created,omitempty"`
+	LastUsed        string     `json:"lastUsed,omitempty"`
 }
 
 
@@ -265,6 +269,17 @@ type AuthorizationTokenResponse struct {
 	ExpiresIn    int    `json:"expires_in"`
 	Scope        string `json:"scope"`
 	RefreshToken string `json:"refresh_token"`
+	Error            string `json:"error,omitempty"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type DeviceCodeResponse struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+	Message         string `json:"message"`
 }
 
 type Xui struct {
@@ -337,6 +352,10 @@ type CompleteLoginResponse struct {
 	MinecraftProfileResponse
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	ObtainedAt   string `json:"obtained_at"`
+	Ownership    MinecraftOwnership `json:"ownership"`
+	Demo         bool   `json:"demo"`
 }
 
 type NewsEntryPlayPageImage struct {
@@ -389,4 +408,278 @@ type JavaPatchNoteEntry struct {
 type JavaPatchNotes struct {
 	Version int                 `json:"version"`
 	Entries []JavaPatchNoteEntry `json:"entries"`
-}
\ No newline at end of file
+}
+type MrpackFileEnv struct {
+	Client string `json:"client"`
+	Server string `json:"server"`
+}
+
+type MrpackFile struct {
+	Path      string            `json:"path"`
+	Hashes    map[string]string `json:"hashes"`
+	Env       *MrpackFileEnv    `json:"env,omitempty"`
+	Downloads []string          `json:"downloads"`
+	FileSize  int64             `json:"fileSize"`
+}
+
+type MrpackIndex struct {
+	FormatVersion int               `json:"formatVersion"`
+	Game          string            `json:"game"`
+	VersionId     string            `json:"versionId"`
+	Name          string            `json:"name"`
+	Summary       string            `json:"summary,omitempty"`
+	Files         []MrpackFile      `json:"files"`
+	Dependencies  map[string]string `json:"dependencies"`
+}
+
+type MrpackInformation struct {
+	Name             string   `json:"name"`
+	Summary          string   `json:"summary"`
+	VersionId        string   `json:"versionId"`
+	FormatVersion    int      `json:"formatVersion"`
+	MinecraftVersion string   `json:"minecraftVersion"`
+	Loader           string   `json:"loader"`
+	LoaderVersion    string   `json:"loaderVersion"`
+	OptionalFiles    []string `json:"optionalFiles"`
+}
+
+type MrpackInstallOptions struct {
+	OptionalFiles           []string `json:"optionalFiles,omitempty"`
+	SkipDependenciesInstall bool     `json:"skipDependenciesInstall,omitempty"`
+}
+
+type CurseForgeManifestModLoader struct {
+	Id      string `json:"id"`
+	Primary bool   `json:"primary"`
+}
+
+type CurseForgeManifestFile struct {
+	ProjectID int  `json:"projectID"`
+	FileID    int  `json:"fileID"`
+	Required  bool `json:"required"`
+}
+
+type CurseForgeManifest struct {
+	Minecraft struct {
+		Version    string                        `json:"version"`
+		ModLoaders []CurseForgeManifestModLoader `json:"modLoaders"`
+	} `json:"minecraft"`
+	ManifestType    string                   `json:"manifestType"`
+	ManifestVersion int                      `json:"manifestVersion"`
+	Name            string                   `json:"name"`
+	Version         string                   `json:"version"`
+	Author          string                   `json:"author"`
+	Files           []CurseForgeManifestFile `json:"files"`
+	Overrides       string                   `json:"overrides"`
+}
+
+type curseForgeFileHash struct {
+	Value string `json:"value"`
+	Algo  int    `json:"algo"`
+}
+
+type curseForgeFile struct {
+	Id          int                  `json:"id"`
+	ModId       int                  `json:"modId"`
+	FileName    string               `json:"fileName"`
+	DownloadUrl *string              `json:"downloadUrl"`
+	Hashes      []curseForgeFileHash `json:"hashes"`
+}
+
+type curseForgeMod struct {
+	Id      int    `json:"id"`
+	Name    string `json:"name"`
+	ClassId int    `json:"classId"`
+	Links   struct {
+		WebsiteUrl string `json:"websiteUrl"`
+	} `json:"links"`
+}
+
+type CurseForgeInstallOptions struct {
+	APIURL string `json:"apiURL,omitempty"`
+	APIKey string `json:"apiKey,omitempty"`
+	SkipDependenciesInstall bool `json:"skipDependenciesInstall,omitempty"`
+}
+
+type CurseForgeSkippedFile struct {
+	ProjectID   int    `json:"projectID"`
+	FileID      int    `json:"fileID"`
+	ProjectName string `json:"projectName"`
+	FileName    string `json:"fileName"`
+	Path        string `json:"path"`
+	WebsiteURL  string `json:"websiteURL"`
+	Reason      string `json:"reason"`
+}
+
+type CurseForgeInstallReport struct {
+	Name          string                  `json:"name"`
+	LaunchVersion string                  `json:"launchVersion"`
+	Installed     []string                `json:"installed"`
+	Skipped       []CurseForgeSkippedFile `json:"skipped"`
+}
+
+type VersionLoaderInfo struct {
+	VersionId        string `json:"versionId"`
+	MinecraftVersion string `json:"minecraftVersion"`
+	Loader           string `json:"loader"`
+	LoaderVersion    string `json:"loaderVersion"`
+}
+
+type MrpackFileLookup func(sha1Hashes []string) (map[string]string, error)
+
+type MrpackExportOptions struct {
+	OutputPath         string           `json:"outputPath"`
+	Name               string           `json:"name"`
+	VersionId          string           `json:"versionId"`
+	Summary            string           `json:"summary,omitempty"`
+	MinecraftDirectory string           `json:"minecraftDirectory,omitempty"`
+	Exclude            []string         `json:"exclude,omitempty"`
+	Lookup             MrpackFileLookup `json:"-"`
+}
+
+type ModrinthVersionFile struct {
+	Hashes   map[string]string `json:"hashes"`
+	Url      string            `json:"url"`
+	Filename string            `json:"filename"`
+	Primary  bool              `json:"primary"`
+	Size     int64             `json:"size"`
+}
+
+type ModrinthVersionDependency struct {
+	VersionId      *string `json:"version_id"`
+	ProjectId      *string `json:"project_id"`
+	FileName       *string `json:"file_name"`
+	DependencyType string  `json:"dependency_type"`
+}
+
+type ModrinthVersion struct {
+	Id            string                      `json:"id"`
+	ProjectId     string                      `json:"project_id"`
+	Name          string                      `json:"name"`
+	VersionNumber string                      `json:"version_number"`
+	Changelog     string                      `json:"changelog"`
+	GameVersions  []string                    `json:"game_versions"`
+	Loaders       []string                    `json:"loaders"`
+	VersionType   string                      `json:"version_type"`
+	DatePublished string                      `json:"date_published"`
+	Files         []ModrinthVersionFile       `json:"files"`
+	Dependencies  []ModrinthVersionDependency `json:"dependencies"`
+}
+
+type ModDependency struct {
+	Id           string `json:"id"`
+	VersionRange string `json:"versionRange,omitempty"`
+	Type         string `json:"type"` // required | optional | breaks | incompatible
+}
+
+type ModInfo struct {
+	FileName         string          `json:"fileName"`
+	Path             string          `json:"path"`
+	Enabled          bool            `json:"enabled"`
+	Id               string          `json:"id"`
+	Name             string          `json:"name"`
+	Version          string          `json:"version"`
+	Description      string          `json:"description,omitempty"`
+	Authors          []string        `json:"authors"`
+	Loader           string          `json:"loader"`
+	MinecraftVersion string          `json:"minecraftVersion,omitempty"`
+	Provides         []string        `json:"provides,omitempty"`
+	Dependencies     []ModDependency `json:"dependencies"`
+	Icon             []byte          `json:"icon,omitempty"`
+	Error            string          `json:"error,omitempty"`
+}
+
+type ModIssue struct {
+	Severity string `json:"severity"` // error | warning
+	Type     string `json:"type"`
+	ModId    string `json:"modId"`
+	FileName string `json:"fileName"`
+	Related  string `json:"related,omitempty"`
+	Message  string `json:"message"`
+}
+
+type ContentEntry struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	FileName string `json:"fileName"`
+	Path     string `json:"path"`
+	Enabled  bool   `json:"enabled"`
+	World    string `json:"world,omitempty"`
+}
+
+type ModUpdateOptions struct {
+	APIURL  string `json:"apiUrl,omitempty"`
+	SiteURL string `json:"siteUrl,omitempty"`
+}
+
+type ModUpdateDependencyChange struct {
+	ProjectId      string `json:"projectId,omitempty"`
+	VersionId      string `json:"versionId,omitempty"`
+	FileName       string `json:"fileName,omitempty"`
+	DependencyType string `json:"dependencyType"`
+	Change         string `json:"change"` // added | removed | changed
+}
+
+type ModUpdate struct {
+	FileName          string                      `json:"fileName"`
+	ProjectId         string                      `json:"projectId"`
+	OldVersionId      string                      `json:"oldVersionId,omitempty"`
+	OldVersion        string                      `json:"oldVersion,omitempty"`
+	NewVersionId      string                      `json:"newVersionId"`
+	NewVersion        string                      `json:"newVersion"`
+	NewFile           ModrinthVersionFile         `json:"newFile"`
+	Changelog         string                      `json:"changelog,omitempty"`
+	ChangelogURL      string                      `json:"changelogUrl,omitempty"`
+	DependencyChanges []ModUpdateDependencyChange `json:"dependencyChanges"`
+}
+
+type ModUpdatePlan struct {
+	VersionId        string      `json:"versionId"`
+	MinecraftVersion string      `json:"minecraftVersion"`
+	Loader           string      `json:"loader"`
+	Updates          []ModUpdate `json:"updates"`
+	Unknown          []string    `json:"unknown"`
+}
+
+type InstanceManifest struct {
+	FormatVersion    int    `json:"formatVersion"`
+	Name             string `json:"name"`
+	VersionId        string `json:"versionId,omitempty"`
+	MinecraftVersion string `json:"minecraftVersion"`
+	Loader           string `json:"loader"`
+	LoaderVersion    string `json:"loaderVersion,omitempty"`
+	AllocatedRAM     int    `json:"allocatedRAM,omitempty"`
+	JVMArguments     string `json:"jvmArguments,omitempty"`
+	ResolutionWidth  int    `json:"resolutionWidth,omitempty"`
+	ResolutionHeight int    `json:"resolutionHeight,omitempty"`
+}
+
+type InstanceExportOptions struct {
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+type MultiMCInstance struct {
+	Path             string `json:"path"`
+	Name             string `json:"name"`
+	GameDirectory    string `json:"gameDirectory"`
+	MinecraftVersion string `json:"minecraftVersion"`
+	Loader           string `json:"loader"`
+	LoaderVersion    string `json:"loaderVersion,omitempty"`
+	MaxMemory        int    `json:"maxMemory,omitempty"`
+	JVMArguments     string `json:"jvmArguments,omitempty"`
+	JavaPath         string `json:"javaPath,omitempty"`
+	WindowWidth      int    `json:"windowWidth,omitempty"`
+	WindowHeight     int    `json:"windowHeight,omitempty"`
+	Launcher         string `json:"launcher,omitempty"`
+}
+
+type MultiMCPackComponent struct {
+	Uid           string `json:"uid"`
+	Version       string `json:"version"`
+	CachedVersion string `json:"cachedVersion"`
+}
+
+type MultiMCPack struct {
+	FormatVersion int                    `json:"formatVersion"`
+	Components    []MultiMCPackComponent `json:"components"`
+}
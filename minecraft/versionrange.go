@@ -0,0 +1,287 @@
+package minecraft
+
+import (
+	"strconv"
+	"strings"
+)
+
+type parsedVersion struct {
+	numbers    []int
+	prerelease string
+}
+
+func parseVersion(version string) (parsedVersion, bool) {
+	version = strings.TrimSpace(version)
+	version, _, _ = strings.Cut(version, "+")
+
+	core, prerelease, _ := strings.Cut(version, "-")
+	if core == "" {
+		return parsedVersion{}, false
+	}
+
+	var numbers []int
+	for _, part := range strings.Split(core, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return parsedVersion{}, false
+		}
+		numbers = append(numbers, n)
+	}
+
+	return parsedVersion{numbers: numbers, prerelease: prerelease}, true
+}
+
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		an, aErr := strconv.Atoi(aParts[i])
+		bn, bErr := strconv.Atoi(bParts[i])
+		switch {
+		case aErr == nil && bErr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case aParts[i] != bParts[i]:
+			if aParts[i] < bParts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(aParts) - len(bParts)
+}
+
+func compareParsedVersions(a, b parsedVersion) int {
+	for i := 0; i < len(a.numbers) || i < len(b.numbers); i++ {
+		var an, bn int
+		if i < len(a.numbers) {
+			an = a.numbers[i]
+		}
+		if i < len(b.numbers) {
+			bn = b.numbers[i]
+		}
+		if an != bn {
+			if an < bn {
+				return -1
+			}
+			return 1
+		}
+	}
+	return comparePrerelease(a.prerelease, b.prerelease)
+}
+
+// CompareVersions returns -1, 0 or 1, and false when either version is not numeric (e.g. snapshots).
+func CompareVersions(a, b string) (int, bool) {
+	av, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	bv, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+	return compareParsedVersions(av, bv), true
+}
+
+// VersionMatchesRange checks a version against a Maven range ("[1.20,1.21)") or a Fabric/semver
+// predicate (">=1.20 <1.21 || 1.19.x"). The second result is false when the match cannot be decided.
+func VersionMatchesRange(version, versionRange string) (bool, bool) {
+	versionRange = strings.TrimSpace(versionRange)
+	if versionRange == "" || versionRange == "*" {
+		return true, true
+	}
+
+	if strings.HasPrefix(versionRange, "[") || strings.HasPrefix(versionRange, "(") {
+		return matchMavenRange(version, versionRange)
+	}
+
+	decided := false
+	for _, alternative := range strings.Split(versionRange, "||") {
+		matches, ok := matchPredicateSet(version, alternative)
+		if !ok {
+			continue
+		}
+		if matches {
+			return true, true
+		}
+		decided = true
+	}
+	return false, decided
+}
+
+func matchMavenRange(version, versionRange string) (bool, bool) {
+	decided := false
+	for len(versionRange) > 0 {
+		end := strings.IndexAny(versionRange, "])")
+		if end < 0 {
+			return false, false
+		}
+
+		matches, ok := matchMavenRestriction(version, versionRange[:end+1])
+		if !ok {
+			return false, false
+		}
+		if matches {
+			return true, true
+		}
+		decided = true
+
+		versionRange = strings.TrimLeft(versionRange[end+1:], ", ")
+	}
+	return false, decided
+}
+
+func matchMavenRestriction(version, restriction string) (bool, bool) {
+	lowerInclusive := restriction[0] == '['
+	upperInclusive := restriction[len(restriction)-1] == ']'
+	inner := restriction[1 : len(restriction)-1]
+
+	lower, upper, isRange := strings.Cut(inner, ",")
+	if !isRange {
+		cmp, ok := CompareVersions(version, strings.TrimSpace(inner))
+		return ok && cmp == 0, ok
+	}
+
+	if lower = strings.TrimSpace(lower); lower != "" {
+		cmp, ok := CompareVersions(version, lower)
+		if !ok {
+			return false, false
+		}
+		if cmp < 0 || (cmp == 0 && !lowerInclusive) {
+			return false, true
+		}
+	}
+
+	if upper = strings.TrimSpace(upper); upper != "" {
+		cmp, ok := CompareVersions(version, upper)
+		if !ok {
+			return false, false
+		}
+		if cmp > 0 || (cmp == 0 && !upperInclusive) {
+			return false, true
+		}
+	}
+
+	return true, true
+}
+
+func matchPredicateSet(version, predicates string) (bool, bool) {
+	fields := strings.Fields(predicates)
+	if len(fields) == 0 {
+		return true, true
+	}
+
+	for _, predicate := range fields {
+		matches, ok := matchPredicate(version, predicate)
+		if !ok {
+			return false, false
+		}
+		if !matches {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+func matchPredicate(version, predicate string) (bool, bool) {
+	if predicate == "*" {
+		return true, true
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<", "=", "~", "^"} {
+		target, found := strings.CutPrefix(predicate, op)
+		if !found {
+			continue
+		}
+
+		switch op {
+		case "~":
+			return matchVersionPrefix(version, target, 2)
+		case "^":
+			return matchVersionPrefix(version, target, 1)
+		}
+
+		if strings.ContainsAny(target, "xX*") {
+			return matchWildcard(version, target)
+		}
+
+		cmp, ok := CompareVersions(version, target)
+		if !ok {
+			return false, false
+		}
+		switch op {
+		case ">=":
+			return cmp >= 0, true
+		case "<=":
+			return cmp <= 0, true
+		case ">":
+			return cmp > 0, true
+		case "<":
+			return cmp < 0, true
+		default:
+			return cmp == 0, true
+		}
+	}
+
+	if strings.ContainsAny(predicate, "xX*") {
+		return matchWildcard(version, predicate)
+	}
+
+	if cmp, ok := CompareVersions(version, predicate); ok {
+		return cmp == 0, true
+	}
+	return version == predicate, true
+}
+
+func matchVersionPrefix(version, target string, prefixLength int) (bool, bool) {
+	v, ok := parseVersion(version)
+	if !ok {
+		return false, false
+	}
+	t, ok := parseVersion(target)
+	if !ok {
+		return false, false
+	}
+
+	if compareParsedVersions(v, t) < 0 {
+		return false, true
+	}
+	for i := 0; i < prefixLength && i < len(t.numbers); i++ {
+		if i >= len(v.numbers) || v.numbers[i] != t.numbers[i] {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+func matchWildcard(version, pattern string) (bool, bool) {
+	v, ok := parseVersion(version)
+	if !ok {
+		return false, false
+	}
+
+	for i, part := range strings.Split(pattern, ".") {
+		if part == "x" || part == "X" || part == "*" {
+			return true, true
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return false, false
+		}
+		if i >= len(v.numbers) || v.numbers[i] != n {
+			return false, true
+		}
+	}
+	return true, true
+}
@@ -0,0 +1,338 @@
+package minecraft
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	AuthlibInjectorMetadataURL = "https://authlib-injector.yushi.moe/artifact/latest.json"
+	AuthlibInjectorFileName    = "authlib-injector.jar"
+
+	_authlibInjectorLocationHeader = "X-Authlib-Injector-API-Location"
+)
+
+var ErrorYggdrasilNoProfile = errors.New("the account has no game profile on this server")
+
+type YggdrasilProfile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type YggdrasilAuthenticateResponse struct {
+	AccessToken       string             `json:"accessToken"`
+	ClientToken       string             `json:"clientToken"`
+	AvailableProfiles []YggdrasilProfile `json:"availableProfiles"`
+	SelectedProfile   *YggdrasilProfile  `json:"selectedProfile"`
+}
+
+// YggdrasilError is the error body every Yggdrasil endpoint returns, e.g. ForbiddenOperationException.
+type YggdrasilError struct {
+	Status       int    `json:"-"`
+	Type         string `json:"error"`
+	ErrorMessage string `json:"errorMessage"`
+	Cause        string `json:"cause,omitempty"`
+}
+
+func (e *YggdrasilError) Error() string {
+	if e.ErrorMessage != "" {
+		return e.ErrorMessage
+	}
+	if e.Type != "" {
+		return e.Type
+	}
+	return fmt.Sprintf("authentication server returned status %d", e.Status)
+}
+
+type AuthlibInjectorArtifact struct {
+	BuildNumber int    `json:"build_number"`
+	Version     string `json:"version"`
+	DownloadURL string `json:"download_url"`
+	Checksums   struct {
+		SHA256 string `json:"sha256"`
+	} `json:"checksums"`
+}
+
+// ResolveYggdrasilAPIRoot follows authlib-injector's API location indication, so users can enter the
+// server's homepage instead of its exact API root.
+func ResolveYggdrasilAPIRoot(rawURL string) (string, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("GET", base.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", getUserAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if location := resp.Header.Get(_authlibInjectorLocationHeader); location != "" {
+		resolved, err := base.Parse(location)
+		if err != nil {
+			return "", err
+		}
+		base = resolved
+	}
+	return strings.TrimSuffix(base.String(), "/"), nil
+}
+
+// GetYggdrasilMetadata returns the raw metadata document served at the API root; authlib-injector takes it
+// unchanged as its prefetched configuration.
+func GetYggdrasilMetadata(apiRoot string) ([]byte, error) {
+	req, err := http.NewRequest("GET", apiRoot+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", getUserAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch authentication server metadata: %s", resp.Status)
+	}
+	if !json.Valid(data) {
+		return nil, errors.New("authentication server metadata is not valid JSON")
+	}
+	return data, nil
+}
+
+func postYggdrasil(apiRoot, endpoint string, body any, result any) (int, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest("POST", apiRoot+"/authserver/"+endpoint, bytes.NewReader(jsonBody))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", getUserAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		yggdrasilErr := &YggdrasilError{Status: resp.StatusCode}
+		json.NewDecoder(resp.Body).Decode(yggdrasilErr)
+		return resp.StatusCode, yggdrasilErr
+	}
+	if result != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return resp.StatusCode, err
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+func YggdrasilAuthenticate(apiRoot, username, password, clientToken string) (*YggdrasilAuthenticateResponse, error) {
+	body := map[string]any{
+		"agent": map[string]any{
+			"name":    "Minecraft",
+			"version": 1,
+		},
+		"username":    username,
+		"password":    password,
+		"clientToken": clientToken,
+		"requestUser": true,
+	}
+
+	var result YggdrasilAuthenticateResponse
+	if _, err := postYggdrasil(apiRoot, "authenticate", body, &result); err != nil {
+		return nil, err
+	}
+
+	// Servers with several profiles per user leave the choice to the launcher; take the first one.
+	if result.SelectedProfile == nil && len(result.AvailableProfiles) > 0 {
+		return YggdrasilRefresh(apiRoot, result.AccessToken, result.ClientToken, &result.AvailableProfiles[0])
+	}
+	if result.SelectedProfile == nil {
+		return nil, ErrorYggdrasilNoProfile
+	}
+	return &result, nil
+}
+
+// YggdrasilRefresh exchanges an access token for a new one, optionally binding it to a profile.
+func YggdrasilRefresh(apiRoot, accessToken, clientToken string, profile *YggdrasilProfile) (*YggdrasilAuthenticateResponse, error) {
+	body := map[string]any{
+		"accessToken": accessToken,
+		"clientToken": clientToken,
+		"requestUser": true,
+	}
+	if profile != nil {
+		body["selectedProfile"] = profile
+	}
+
+	var result YggdrasilAuthenticateResponse
+	if _, err := postYggdrasil(apiRoot, "refresh", body, &result); err != nil {
+		return nil, err
+	}
+	if result.SelectedProfile == nil {
+		return nil, ErrorYggdrasilNoProfile
+	}
+	return &result, nil
+}
+
+// YggdrasilValidate reports whether the access token is still accepted by the server.
+func YggdrasilValidate(apiRoot, accessToken, clientToken string) (bool, error) {
+	body := map[string]any{
+		"accessToken": accessToken,
+		"clientToken": clientToken,
+	}
+
+	status, err := postYggdrasil(apiRoot, "validate", body, nil)
+	if status == http.StatusForbidden || status == http.StatusUnauthorized {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func YggdrasilInvalidate(apiRoot, accessToken, clientToken string) error {
+	body := map[string]any{
+		"accessToken": accessToken,
+		"clientToken": clientToken,
+	}
+
+	_, err := postYggdrasil(apiRoot, "invalidate", body, nil)
+	return err
+}
+
+func getSHA256Hash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// InstallAuthlibInjector makes sure the latest authlib-injector is in dir and returns its path. metadataURL
+// defaults to AuthlibInjectorMetadataURL; an existing jar is kept when the metadata cannot be fetched.
+func InstallAuthlibInjector(dir, metadataURL string) (string, error) {
+	if metadataURL == "" {
+		metadataURL = AuthlibInjectorMetadataURL
+	}
+	path := filepath.Join(dir, AuthlibInjectorFileName)
+
+	var artifact AuthlibInjectorArtifact
+	data, err := getRequestsResponseCache(metadataURL)
+	if err == nil {
+		err = json.Unmarshal(data, &artifact)
+	}
+	if err != nil || artifact.DownloadURL == "" {
+		if _, statErr := os.Stat(path); statErr == nil {
+			return path, nil
+		}
+		if err == nil {
+			err = errors.New("authlib-injector metadata has no download URL")
+		}
+		return "", fmt.Errorf("failed to fetch authlib-injector metadata: %w", err)
+	}
+
+	if hash, err := getSHA256Hash(path); err == nil && strings.EqualFold(hash, artifact.Checksums.SHA256) {
+		return path, nil
+	}
+
+	tmpPath := path + ".part"
+	if err := downloadFile(artifact.DownloadURL, tmpPath, "", "", true); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if artifact.Checksums.SHA256 != "" {
+		hash, err := getSHA256Hash(tmpPath)
+		if err != nil {
+			os.Remove(tmpPath)
+			return "", err
+		}
+		if !strings.EqualFold(hash, artifact.Checksums.SHA256) {
+			os.Remove(tmpPath)
+			return "", fmt.Errorf("invalid authlib-injector checksum: expected %s, got %s", artifact.Checksums.SHA256, hash)
+		}
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return path, nil
+}
+
+// getAuthlibInjectorArguments returns the JVM arguments that point the game at a Yggdrasil server.
+func getAuthlibInjectorArguments(options MinecraftOptions) []string {
+	if options.AuthlibInjectorPath == "" || options.YggdrasilAPIURL == "" {
+		return nil
+	}
+
+	arguments := []string{"-javaagent:" + options.AuthlibInjectorPath + "=" + options.YggdrasilAPIURL}
+	if options.YggdrasilMetadata != "" {
+		arguments = append(arguments, "-Dauthlibinjector.yggdrasil.prefetched="+base64.StdEncoding.EncodeToString([]byte(options.YggdrasilMetadata)))
+	}
+	return arguments
+}
+
+// YggdrasilLogin is a finished login against a third-party authentication server.
+type YggdrasilLogin struct {
+	APIRoot     string           `json:"apiRoot"`
+	AccessToken string           `json:"accessToken"`
+	ClientToken string           `json:"clientToken"`
+	Profile     YggdrasilProfile `json:"profile"`
+}
+
+func CompleteYggdrasilLogin(server, username, password, clientToken string) (*YggdrasilLogin, error) {
+	apiRoot, err := ResolveYggdrasilAPIRoot(server)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := YggdrasilAuthenticate(apiRoot, username, password, clientToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &YggdrasilLogin{
+		APIRoot:     apiRoot,
+		AccessToken: resp.AccessToken,
+		ClientToken: resp.ClientToken,
+		Profile:     *resp.SelectedProfile,
+	}, nil
+}
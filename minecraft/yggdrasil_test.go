@@ -0,0 +1,420 @@
+package minecraft
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var _testYggdrasilProfile = YggdrasilProfile{ID: "4566e69fc90748ee8d71d7ba5aa00d20", Name: "Steve"}
+
+// newYggdrasilServer serves the authserver endpoints of a fake Yggdrasil server under /api/yggdrasil.
+// It knows one user, "steve@example.com" with password "secret", who owns profiles.
+func newYggdrasilServer(t *testing.T, profiles []YggdrasilProfile) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(_authlibInjectorLocationHeader, "/api/yggdrasil/")
+		w.Write([]byte("<html>homepage</html>"))
+	})
+	mux.HandleFunc("/api/yggdrasil/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"meta":{"serverName":"Test"},"skinDomains":["example.com"]}`))
+	})
+	mux.HandleFunc("/api/yggdrasil/authserver/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Username    string `json:"username"`
+			Password    string `json:"password"`
+			ClientToken string `json:"clientToken"`
+			Agent       struct {
+				Name string `json:"name"`
+			} `json:"agent"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Agent.Name != "Minecraft" {
+			t.Errorf("authenticate: agent = %q, want Minecraft", body.Agent.Name)
+		}
+		if body.Username != "steve@example.com" || body.Password != "secret" {
+			writeYggdrasilError(w, http.StatusForbidden, "Invalid credentials. Invalid username or password.")
+			return
+		}
+
+		resp := YggdrasilAuthenticateResponse{
+			AccessToken:       "access-1",
+			ClientToken:       body.ClientToken,
+			AvailableProfiles: profiles,
+		}
+		if len(profiles) == 1 {
+			resp.SelectedProfile = &profiles[0]
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/api/yggdrasil/authserver/refresh", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			AccessToken     string            `json:"accessToken"`
+			ClientToken     string            `json:"clientToken"`
+			SelectedProfile *YggdrasilProfile `json:"selectedProfile"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.AccessToken != "access-1" {
+			writeYggdrasilError(w, http.StatusForbidden, "Invalid token.")
+			return
+		}
+
+		resp := YggdrasilAuthenticateResponse{
+			AccessToken: "access-2",
+			ClientToken: body.ClientToken,
+		}
+		if body.SelectedProfile != nil {
+			resp.SelectedProfile = body.SelectedProfile
+		} else if len(profiles) == 1 {
+			resp.SelectedProfile = &profiles[0]
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/api/yggdrasil/authserver/validate", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			AccessToken string `json:"accessToken"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.AccessToken != "access-1" {
+			writeYggdrasilError(w, http.StatusForbidden, "Invalid token.")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/api/yggdrasil/authserver/invalidate", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeYggdrasilError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(YggdrasilError{Type: "ForbiddenOperationException", ErrorMessage: message})
+}
+
+func TestResolveYggdrasilAPIRoot(t *testing.T) {
+	server := newYggdrasilServer(t, []YggdrasilProfile{_testYggdrasilProfile})
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"follows the API location header", server.URL, server.URL + "/api/yggdrasil"},
+		{"keeps the URL without the header", plain.URL + "/", plain.URL},
+		{"adds a scheme", strings.TrimPrefix(plain.URL, "http://"), ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ResolveYggdrasilAPIRoot(test.url)
+			if test.want == "" {
+				// httptest servers only speak plain http, so the https default cannot connect.
+				if err == nil {
+					t.Fatalf("ResolveYggdrasilAPIRoot(%q) = %q, want a TLS error", test.url, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveYggdrasilAPIRoot(%q): %v", test.url, err)
+			}
+			if got != test.want {
+				t.Errorf("ResolveYggdrasilAPIRoot(%q) = %q, want %q", test.url, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetYggdrasilMetadata(t *testing.T) {
+	server := newYggdrasilServer(t, nil)
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down/" {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("<html>not json</html>"))
+	}))
+	defer broken.Close()
+
+	data, err := GetYggdrasilMetadata(server.URL + "/api/yggdrasil")
+	if err != nil {
+		t.Fatalf("GetYggdrasilMetadata: %v", err)
+	}
+	if !strings.Contains(string(data), `"serverName":"Test"`) {
+		t.Errorf("GetYggdrasilMetadata = %s, want the served document", data)
+	}
+
+	if _, err := GetYggdrasilMetadata(broken.URL); err == nil {
+		t.Error("GetYggdrasilMetadata accepted a non-JSON document")
+	}
+	if _, err := GetYggdrasilMetadata(broken.URL + "/down"); err == nil {
+		t.Error("GetYggdrasilMetadata accepted an error status")
+	}
+}
+
+func TestYggdrasilAuthenticate(t *testing.T) {
+	other := YggdrasilProfile{ID: "0f3a2ab2c7e54de7a1ddb5a3b0d8a9c1", Name: "Alex"}
+
+	tests := []struct {
+		name        string
+		profiles    []YggdrasilProfile
+		password    string
+		wantProfile string
+		wantToken   string
+		wantErr     error
+		wantStatus  int
+	}{
+		{"selected profile", []YggdrasilProfile{_testYggdrasilProfile}, "secret", "Steve", "access-1", nil, 0},
+		{"picks the first of several profiles", []YggdrasilProfile{other, _testYggdrasilProfile}, "secret", "Alex", "access-2", nil, 0},
+		{"no profile", nil, "secret", "", "", ErrorYggdrasilNoProfile, 0},
+		{"wrong password", []YggdrasilProfile{_testYggdrasilProfile}, "wrong", "", "", nil, http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newYggdrasilServer(t, test.profiles)
+			apiRoot := server.URL + "/api/yggdrasil"
+
+			resp, err := YggdrasilAuthenticate(apiRoot, "steve@example.com", test.password, "client-1")
+			if test.wantStatus != 0 {
+				var yggdrasilErr *YggdrasilError
+				if !errors.As(err, &yggdrasilErr) {
+					t.Fatalf("YggdrasilAuthenticate error = %v, want a *YggdrasilError", err)
+				}
+				if yggdrasilErr.Status != test.wantStatus || yggdrasilErr.Type != "ForbiddenOperationException" {
+					t.Errorf("YggdrasilAuthenticate error = %+v, want status %d", yggdrasilErr, test.wantStatus)
+				}
+				return
+			}
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("YggdrasilAuthenticate error = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("YggdrasilAuthenticate: %v", err)
+			}
+			if resp.SelectedProfile.Name != test.wantProfile || resp.AccessToken != test.wantToken || resp.ClientToken != "client-1" {
+				t.Errorf("YggdrasilAuthenticate = %+v, want profile %s with token %s", resp, test.wantProfile, test.wantToken)
+			}
+		})
+	}
+}
+
+func TestYggdrasilRefresh(t *testing.T) {
+	server := newYggdrasilServer(t, []YggdrasilProfile{_testYggdrasilProfile})
+	apiRoot := server.URL + "/api/yggdrasil"
+
+	resp, err := YggdrasilRefresh(apiRoot, "access-1", "client-1", nil)
+	if err != nil {
+		t.Fatalf("YggdrasilRefresh: %v", err)
+	}
+	if resp.AccessToken != "access-2" || resp.SelectedProfile.ID != _testYggdrasilProfile.ID {
+		t.Errorf("YggdrasilRefresh = %+v, want a new token for %s", resp, _testYggdrasilProfile.Name)
+	}
+
+	_, err = YggdrasilRefresh(apiRoot, "revoked", "client-1", nil)
+	var yggdrasilErr *YggdrasilError
+	if !errors.As(err, &yggdrasilErr) || yggdrasilErr.Status != http.StatusForbidden {
+		t.Errorf("YggdrasilRefresh with a revoked token = %v, want a 403 *YggdrasilError", err)
+	}
+}
+
+func TestYggdrasilValidate(t *testing.T) {
+	server := newYggdrasilServer(t, nil)
+	apiRoot := server.URL + "/api/yggdrasil"
+
+	tests := []struct {
+		token string
+		want  bool
+	}{
+		{"access-1", true},
+		{"revoked", false},
+	}
+	for _, test := range tests {
+		valid, err := YggdrasilValidate(apiRoot, test.token, "client-1")
+		if err != nil {
+			t.Fatalf("YggdrasilValidate(%q): %v", test.token, err)
+		}
+		if valid != test.want {
+			t.Errorf("YggdrasilValidate(%q) = %v, want %v", test.token, valid, test.want)
+		}
+	}
+
+	server.Close()
+	if _, err := YggdrasilValidate(apiRoot, "access-1", "client-1"); err == nil {
+		t.Error("YggdrasilValidate against a closed server returned no error")
+	}
+}
+
+func TestYggdrasilInvalidate(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/authserver/invalidate" || r.Method != "POST" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := YggdrasilInvalidate(server.URL, "access-1", "client-1"); err != nil {
+		t.Fatalf("YggdrasilInvalidate: %v", err)
+	}
+	if got["accessToken"] != "access-1" || got["clientToken"] != "client-1" {
+		t.Errorf("YggdrasilInvalidate sent %v, want both tokens", got)
+	}
+}
+
+func TestCompleteYggdrasilLogin(t *testing.T) {
+	server := newYggdrasilServer(t, []YggdrasilProfile{_testYggdrasilProfile})
+
+	login, err := CompleteYggdrasilLogin(server.URL, "steve@example.com", "secret", "client-1")
+	if err != nil {
+		t.Fatalf("CompleteYggdrasilLogin: %v", err)
+	}
+	want := YggdrasilLogin{
+		APIRoot:     server.URL + "/api/yggdrasil",
+		AccessToken: "access-1",
+		ClientToken: "client-1",
+		Profile:     _testYggdrasilProfile,
+	}
+	if *login != want {
+		t.Errorf("CompleteYggdrasilLogin = %+v, want %+v", *login, want)
+	}
+}
+
+// newAuthlibInjectorServer serves authlib-injector metadata announcing checksum for the jar it serves.
+func newAuthlibInjectorServer(t *testing.T, jar []byte, checksum string, downloads *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest.json", func(w http.ResponseWriter, r *http.Request) {
+		var artifact AuthlibInjectorArtifact
+		artifact.BuildNumber = 53
+		artifact.Version = "1.2.5"
+		artifact.DownloadURL = server.URL + "/authlib-injector-1.2.5.jar"
+		artifact.Checksums.SHA256 = checksum
+		json.NewEncoder(w).Encode(artifact)
+	})
+	mux.HandleFunc("/authlib-injector-1.2.5.jar", func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		w.Write(jar)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestInstallAuthlibInjector(t *testing.T) {
+	jar := []byte("PK\x03\x04 fake authlib-injector jar")
+	sum := sha256.Sum256(jar)
+	checksum := hex.EncodeToString(sum[:])
+
+	t.Run("downloads and verifies", func(t *testing.T) {
+		var downloads atomic.Int32
+		server := newAuthlibInjectorServer(t, jar, strings.ToUpper(checksum), &downloads)
+		dir := t.TempDir()
+
+		path, err := InstallAuthlibInjector(dir, server.URL+"/latest.json")
+		if err != nil {
+			t.Fatalf("InstallAuthlibInjector: %v", err)
+		}
+		if path != filepath.Join(dir, AuthlibInjectorFileName) {
+			t.Errorf("InstallAuthlibInjector path = %q", path)
+		}
+		if data, _ := os.ReadFile(path); string(data) != string(jar) {
+			t.Errorf("installed jar = %q, want %q", data, jar)
+		}
+
+		// A jar that already matches the checksum is not downloaded again.
+		if _, err := InstallAuthlibInjector(dir, server.URL+"/latest.json"); err != nil {
+			t.Fatalf("InstallAuthlibInjector again: %v", err)
+		}
+		if downloads.Load() != 1 {
+			t.Errorf("jar downloaded %d times, want 1", downloads.Load())
+		}
+	})
+
+	t.Run("rejects a checksum mismatch", func(t *testing.T) {
+		var downloads atomic.Int32
+		server := newAuthlibInjectorServer(t, jar, strings.Repeat("0", 64), &downloads)
+		dir := t.TempDir()
+
+		if _, err := InstallAuthlibInjector(dir, server.URL+"/latest.json"); err == nil || !strings.Contains(err.Error(), "checksum") {
+			t.Fatalf("InstallAuthlibInjector error = %v, want a checksum error", err)
+		}
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 0 {
+			t.Errorf("InstallAuthlibInjector left %d files behind after a bad download", len(entries))
+		}
+	})
+
+	t.Run("replaces a tampered jar", func(t *testing.T) {
+		var downloads atomic.Int32
+		server := newAuthlibInjectorServer(t, jar, checksum, &downloads)
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, AuthlibInjectorFileName), []byte("tampered"), 0644)
+
+		path, err := InstallAuthlibInjector(dir, server.URL+"/latest.json")
+		if err != nil {
+			t.Fatalf("InstallAuthlibInjector: %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != string(jar) || downloads.Load() != 1 {
+			t.Errorf("tampered jar was not replaced: %q after %d downloads", data, downloads.Load())
+		}
+	})
+
+	t.Run("keeps an existing jar when metadata is unavailable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		dir := t.TempDir()
+
+		if _, err := InstallAuthlibInjector(dir, server.URL+"/latest.json"); err == nil {
+			t.Fatal("InstallAuthlibInjector without metadata or a jar returned no error")
+		}
+		os.WriteFile(filepath.Join(dir, AuthlibInjectorFileName), jar, 0644)
+		if _, err := InstallAuthlibInjector(dir, server.URL+"/latest.json"); err != nil {
+			t.Errorf("InstallAuthlibInjector with an existing jar: %v", err)
+		}
+	})
+}
+
+func TestGetAuthlibInjectorArguments(t *testing.T) {
+	metadata := `{"meta":{}}`
+	options := MinecraftOptions{
+		AuthlibInjectorPath: "/data/authlib-injector.jar",
+		YggdrasilAPIURL:     "https://example.com/api/yggdrasil",
+		YggdrasilMetadata:   metadata,
+	}
+
+	got := getAuthlibInjectorArguments(options)
+	want := []string{
+		"-javaagent:/data/authlib-injector.jar=https://example.com/api/yggdrasil",
+		"-Dauthlibinjector.yggdrasil.prefetched=" + base64.StdEncoding.EncodeToString([]byte(metadata)),
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("getAuthlibInjectorArguments = %v, want %v", got, want)
+	}
+
+	if got := getAuthlibInjectorArguments(MinecraftOptions{}); got != nil {
+		t.Errorf("getAuthlibInjectorArguments without a server = %v, want none", got)
+	}
+}